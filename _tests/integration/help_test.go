@@ -20,12 +20,33 @@ VERSION:
    %s
 
 COMMANDS:
+     scan     Scan the project and print the scan result (options, configs, warnings and errors)
+     explain  Explain the detection decisions: the files every scanner looked for and found, the candidates it left out, and why it did, or did not detect its platform
      help, h  Shows a list of commands or help for one command
 
 GLOBAL OPTIONS:
-   --minimal      create empty bitrise config and secrets
-   --help, -h     show help
-   --version, -v  print the version`, version.VERSION)
+   --minimal                create empty bitrise config and secrets
+   --dir value              directory to scan, defaults to the current directory
+   --scanners value         run only the given scanners, comma separated, like android,fastlane
+   --skip-scanners value    do not run the given scanners, comma separated, like cordova
+   --include value          scan only the paths matching the gitignore style pattern, relative to the scanned directory, like apps/, can be repeated
+   --exclude value          do not scan the paths matching the gitignore style pattern, relative to the scanned directory, like samples/, can be repeated
+   --timeout value          time limit of the scan, like 10m, the scanners still running then are reported as failed, 0s means no limit (default: 0s)
+   --scanner-timeout value  time budget of a scanner as NAME=DURATION, like ios=15m, overriding its default budget, can be repeated
+   --no-cache               do not reuse or store the cached scanner outputs, the outputs are cached by the files the scanners read
+   --config value           path of the generated bitrise config (default: "./bitrise.yml")
+   --secrets value          path of the generated bitrise secrets (default: "./.bitrise.secrets.yml")
+   --overwrite value        what to do if the config or secrets already exist: fail, backup (timestamped copy) or overwrite (default: "fail")
+   --merge                  merge the detected workflows, app envs and triggers into the existing bitrise config
+   --dry-run                print the changes of the bitrise config, secrets and .gitignore as unified diffs, instead of writing them
+   --answers value          answer the config questions from the given YAML or JSON file (keyed by option title or env key), instead of asking for them
+   --record value           save the selected platform, options and config to the given replay file
+   --replay value           re-run the selections saved in the given replay file against a fresh scan, instead of asking for them
+   --web                    answer the config questions in the browser, served by a local web server
+   --web-addr value         address of the local web server started with --web, on a free port by default (default: "localhost:0")
+   --no-tui                 ask the config questions as numbered lists, instead of the full-screen terminal UI
+   --help, -h               show help
+   --version, -v            print the version`, version.VERSION)
 
 func Test_HelpTest(t *testing.T) {
 	t.Log("help command")
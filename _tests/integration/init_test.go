@@ -1,10 +1,12 @@
 package integration
 
 import (
-	"testing"
-
+	"bytes"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"testing"
 
 	"github.com/bitrise-io/go-utils/command"
 	"github.com/bitrise-io/go-utils/pathutil"
@@ -103,4 +105,40 @@ func Test_InitTest(t *testing.T) {
 		out, err := cmd.RunAndReturnTrimmedCombinedOutput()
 		require.EqualError(t, err, "exit status 1", out)
 	}
+
+}
+
+func Test_InitDryRun(t *testing.T) {
+	t.Log("init --dry-run - bitrise.yml already exists - SHOULD SUCCEED, printing only the diffs to the stdout")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("")
+		require.NoError(t, err)
+
+		for pth, content := range map[string]string{
+			"bitrise.yml":      "format_version: \"8\"\n",
+			"settings.gradle":  "include ':app'\n",
+			"build.gradle":     "",
+			"gradlew":          "",
+			"app/build.gradle": "apply plugin: 'com.android.application'\n",
+		} {
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, pth)), 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, pth), []byte(content), 0644))
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := command.New(binPath(), "--dry-run", "--no-tui")
+		cmd.SetDir(tmpDir)
+		cmd.SetStdin(strings.NewReader(strings.Repeat("\n", 20)))
+		cmd.SetStdout(&stdout)
+		cmd.SetStderr(&stderr)
+		require.NoError(t, cmd.Run(), stderr.String())
+
+		require.True(t, strings.HasPrefix(stdout.String(), "--- "), stdout.String())
+		require.NotContains(t, stdout.String(), "Type in the option's number")
+		require.Contains(t, stderr.String(), "Type in the option's number")
+
+		content, err := ioutil.ReadFile(filepath.Join(tmpDir, "bitrise.yml"))
+		require.NoError(t, err)
+		require.Equal(t, "format_version: \"8\"\n", string(content))
+	}
 }
@@ -4,40 +4,188 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/bitrise-io/bitrise-init/models"
 	"github.com/bitrise-io/bitrise-init/scanner"
 	"github.com/bitrise-io/bitrise-init/scanners"
 	bitriseModels "github.com/bitrise-io/bitrise/models"
-	envmanModels "github.com/bitrise-io/envman/models"
 	"github.com/bitrise-io/go-utils/fileutil"
 	"github.com/bitrise-io/go-utils/pathutil"
 	"github.com/urfave/cli"
 )
 
 func action(c *cli.Context) error {
+	if err := exclusiveFlags(c, "minimal", "answers", "replay", "web"); err != nil {
+		return err
+	}
+
 	minimal := c.Bool("minimal")
+	dryRun := c.Bool("dry-run")
+	// the questions are printed to the stdout, unless it is kept for the diffs
+	promptOut := os.Stdout
+	if dryRun {
+		// the diffs are printed to the stdout, the scanner logs and the questions are redirected to keep it clean
+		scanner.SetLogOutWriter(os.Stderr)
+		promptOut = os.Stderr
+	}
+	prompt := scanner.NewPrompt(os.Stdin, promptOut, !c.Bool("no-tui"))
 
-	configPth := "./bitrise.yml"
-	if exist, err := pathutil.IsPathExists(configPth); err != nil {
-		return err
-	} else if exist {
-		return fmt.Errorf("config path (%s) already exist", configPth)
+	var a answers
+	if answersPth := c.String("answers"); answersPth != "" {
+		var err error
+		if a, err = readAnswers(answersPth); err != nil {
+			return err
+		}
 	}
 
-	secretsPth := "./.bitrise.secrets.yml"
-	if exist, err := pathutil.IsPathExists(secretsPth); err != nil {
+	replayPth := c.String("replay")
+	var recorded scanner.Selection
+	if replayPth != "" {
+		var err error
+		if recorded, err = readSelection(replayPth); err != nil {
+			return err
+		}
+	}
+
+	policy, err := parseOverwritePolicy(c.String("overwrite"))
+	if err != nil {
 		return err
-	} else if exist {
-		return fmt.Errorf("secrets path (%s) already exist", secretsPth)
+	}
+
+	configPth := c.String("config")
+	secretsPth := c.String("secrets")
+
+	// in merge mode the existing config and the existing secrets are extended
+	merge := c.Bool("merge")
+	var existingConfig *configDocument
+	var existingSecrets []byte
+	if merge {
+		if exist, err := pathutil.IsPathExists(configPth); err != nil {
+			return err
+		} else if exist {
+			existingConfig, err = readConfig(configPth)
+			if err != nil {
+				return err
+			}
+		}
+
+		if exist, err := pathutil.IsPathExists(secretsPth); err != nil {
+			return err
+		} else if exist {
+			existingSecrets, err = ioutil.ReadFile(secretsPth)
+			if err != nil {
+				return fmt.Errorf("failed to read bitrise secrets (%s), error: %s", secretsPth, err)
+			}
+		}
+	} else if policy == overwritePolicyFail && !dryRun {
+		// nothing is written in dry-run mode
+		if exist, err := pathutil.IsPathExists(configPth); err != nil {
+			return err
+		} else if exist {
+			return fmt.Errorf("config path (%s) already exist", configPth)
+		}
+
+		if exist, err := pathutil.IsPathExists(secretsPth); err != nil {
+			return err
+		} else if exist {
+			return fmt.Errorf("secrets path (%s) already exist", secretsPth)
+		}
+	}
+
+	secretsName := filepath.Base(secretsPth)
+	gitignorePth := filepath.Join(filepath.Dir(secretsPth), ".gitignore")
+
+	// the scan result, the secrets of the selected config are written with the config
+	var scanResult models.ScanResultModel
+
+	// write writes the outputs of the generated config, or prints their diffs in dry-run mode
+	write := func(bitriseConfig bitriseModels.BitriseDataModel, selection scanner.Selection) error {
+		configBytes, err := existingConfig.marshal(bitriseConfig)
+		if err != nil {
+			return fmt.Errorf("failed to marshal bitrise config, error: %s", err)
+		}
+
+		secrets := scanner.BuildSecrets(scanResult, selection)
+		secretsBytes, err := yaml.Marshal(secrets)
+		if err != nil {
+			return fmt.Errorf("failed to marshal bitrise secrets, error: %s", err)
+		}
+
+		// the existing secrets are written only if the selected config adds secrets to them
+		writeSecrets := true
+		if existingSecrets != nil {
+			var addedKeys []string
+			if secretsBytes, addedKeys, err = mergeSecrets(existingSecrets, secrets); err != nil {
+				return err
+			}
+			writeSecrets = len(addedKeys) > 0
+		}
+
+		if dryRun {
+			outputs := []outputFile{{configPth, string(configBytes)}}
+			if writeSecrets {
+				outputs = append(outputs, outputFile{secretsPth, string(secretsBytes)})
+			}
+
+			gitignoreContent, err := ioutil.ReadFile(gitignorePth)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to read %s, error: %s", gitignorePth, err)
+			}
+			addition, err := gitignoreAddition(secretsName, string(gitignoreContent))
+			if err != nil {
+				return fmt.Errorf("matching .gitignore file contents at %s against %s: %s", gitignorePth, secretsName, err)
+			}
+			outputs = append(outputs, outputFile{gitignorePth, string(gitignoreContent) + addition})
+
+			return printDiffs(outputs...)
+		}
+
+		outputPths := []string{configPth}
+		if writeSecrets {
+			outputPths = append(outputPths, secretsPth)
+		}
+		if err := backupOutputs(policy, time.Now(), outputPths...); err != nil {
+			return err
+		}
+
+		if err := fileutil.WriteBytesToFile(configPth, configBytes); err != nil {
+			return fmt.Errorf("failed to write bitrise config, error: %s", err)
+		}
+
+		log.Infof("bitrise config generated at: %s", configPth)
+
+		if writeSecrets {
+			if err := fileutil.WriteBytesToFile(secretsPth, secretsBytes); err != nil {
+				return fmt.Errorf("failed to write bitrise secrets, error: %s", err)
+			}
+
+			log.Infof("bitrise secrets generated at: %s", secretsPth)
+		}
+
+		if err := gitignore(secretsName, gitignorePth); err != nil {
+			log.Warnf("Could not add %s to .gitignore: %s", secretsName, err)
+			log.Warnf("Please be advised, that for security considerations, it is not recommended to upload %s to version control", secretsName)
+		}
+
+		if recordPth := c.String("record"); recordPth != "" && selection.ConfigName != "" {
+			if err := writeSelection(recordPth, selection); err != nil {
+				return err
+			}
+
+			log.Infof("selected options recorded at: %s", recordPth)
+		}
+
+		return nil
 	}
 
 	// generate config
-	bitriseConfig := bitriseModels.BitriseDataModel{}
 	if minimal {
 		scanResult, err := scanner.ManualConfig()
 		if err != nil {
@@ -59,58 +207,107 @@ func action(c *cli.Context) error {
 			return fmt.Errorf("no default empty config found, error: %s", err)
 		}
 
-		bitriseConfig = customConfig
-	} else {
-		// run scanner
+		bitriseConfig, conflicts, err := generateConfig(customConfig, existingConfig)
+		if err != nil {
+			return err
+		}
+		logMergeConflicts(conflicts)
+
+		return write(bitriseConfig, scanner.Selection{})
+	}
+
+	// run scanner
+	searchDir := c.String("dir")
+	if searchDir == "" {
 		currentDir, err := os.Getwd()
 		if err != nil {
 			return fmt.Errorf("failed to get current directory, error: %s", err)
 		}
+		searchDir = currentDir
+	}
+
+	filter, err := scanFilter(c)
+	if err != nil {
+		return err
+	}
 
-		scanResult := scanner.Config(currentDir)
+	options, err := scanOptions(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel, err := scanContext(c)
+	if err != nil {
+		return err
+	}
+	defer cancel()
 
-		if len(scanResult.ScannerToOptionRoot) == 0 {
-			return fmt.Errorf("no known platform type detected")
+	scanResult = scanner.ConfigContext(ctx, searchDir, filter, options)
+
+	if len(scanResult.ScannerToOptionRoot) == 0 {
+		if scanResult.Diagnostics != nil {
+			log.Infof("Files in the search dir:\n%s", scanResult.Diagnostics.DirTree)
 		}
+		return fmt.Errorf("no known platform type detected, run the explain command to see why")
+	}
 
-		config, err := scanner.AskForConfig(scanResult)
+	if c.Bool("web") {
+		handler, err := newWizardHandler(scanResult, func(selection scanner.Selection) (bitriseModels.BitriseDataModel, []string, error) {
+			config, err := scanner.BuildConfig(scanResult, selection)
+			if err != nil {
+				return bitriseModels.BitriseDataModel{}, nil, err
+			}
+			return generateConfig(config, existingConfig)
+		}, existingConfig.marshal, write)
 		if err != nil {
 			return err
 		}
-
-		bitriseConfig = config
+		return serveWizard(c.String("web-addr"), handler)
 	}
 
-	// write outputs
-	configBytes, err := yaml.Marshal(bitriseConfig)
+	var selection scanner.Selection
+	if replayPth != "" {
+		selection, err = replaySelection(scanResult, recorded)
+	} else if a != nil {
+		selection, err = answerSelection(scanResult, a)
+	} else {
+		selection, err = prompt.AskForSelection(scanResult)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to marshal bitrise config, error: %s", err)
+		return err
 	}
 
-	if err := fileutil.WriteBytesToFile(configPth, configBytes); err != nil {
-		return fmt.Errorf("failed to write bitrise config, error: %s", err)
+	config, err := scanner.BuildConfig(scanResult, selection)
+	if err != nil {
+		return err
 	}
 
-	log.Infof("bitrise config generated at: %s", configPth)
-
-	secrets := envmanModels.EnvsSerializeModel{}
-	secretsBytes, err := yaml.Marshal(secrets)
+	bitriseConfig, conflicts, err := generateConfig(config, existingConfig)
 	if err != nil {
-		return fmt.Errorf("failed to marshal bitrise secrets, error: %s", err)
+		return err
 	}
+	logMergeConflicts(conflicts)
 
-	if err := fileutil.WriteBytesToFile(secretsPth, secretsBytes); err != nil {
-		return fmt.Errorf("failed to write bitrise secrets, error: %s", err)
-	}
+	return write(bitriseConfig, selection)
+}
 
-	log.Infof("bitrise secrets generated at: %s", secretsPth)
+// generateConfig merges the generated config into the existing config, if there is one.
+func generateConfig(config bitriseModels.BitriseDataModel, existingConfig *configDocument) (bitriseModels.BitriseDataModel, []string, error) {
+	if existingConfig == nil {
+		return config, nil, nil
+	}
 
-	if err := gitignore(".bitrise.secrets.yml", "./.gitignore"); err != nil {
-		log.Warnf("Could not add .bitrise.secrets.yml to .gitignore: %s", err)
-		log.Warnf("Please be advised, that for security considerations, it is not recommended to upload .bitrise.secrets.yml to version control")
+	merged, conflicts, err := mergeConfigs(existingConfig.config, config)
+	if err != nil {
+		return bitriseModels.BitriseDataModel{}, nil, fmt.Errorf("failed to merge bitrise config, error: %s", err)
 	}
+	return merged, conflicts, nil
+}
 
-	return nil
+func logMergeConflicts(conflicts []string) {
+	for _, conflict := range conflicts {
+		log.Warnf("Merge conflict: %s", conflict)
+	}
 }
 
 func gitignore(pattern, gitignorePath string) error {
@@ -125,21 +322,49 @@ func gitignore(pattern, gitignorePath string) error {
 	}()
 
 	contents, err := ioutil.ReadFile(gitignorePath)
-	matched, err := regexp.MatchString(fmt.Sprintf("^%s$", pattern), string(contents))
+	addition, err := gitignoreAddition(pattern, string(contents))
 	if err != nil {
 		return fmt.Errorf("matching .gitignore file contents at %s against %s: %s", gitignorePath, pattern, err)
 	}
-	if matched {
+	if addition == "" {
 		return nil
 	}
 
-	if len(contents) > 0 && !strings.HasSuffix(string(contents), fmt.Sprintln("")) {
-		pattern = "\n" + pattern
+	if _, err := f.WriteString(addition); err != nil {
+		return fmt.Errorf("write pattern to .gitignore at %s: %s", gitignorePath, err)
 	}
 
-	if _, err := f.WriteString(pattern); err != nil {
-		return fmt.Errorf("write pattern to .gitignore at %s: %s", gitignorePath, err)
+	return nil
+}
+
+// gitignoreAddition returns the text to append to the .gitignore contents to ignore the pattern,
+// or an empty string if the pattern is already there.
+func gitignoreAddition(pattern, contents string) (string, error) {
+	matched, err := regexp.MatchString(fmt.Sprintf("^%s$", pattern), contents)
+	if err != nil {
+		return "", err
+	}
+	if matched {
+		return "", nil
 	}
 
+	if len(contents) > 0 && !strings.HasSuffix(contents, fmt.Sprintln("")) {
+		pattern = "\n" + pattern
+	}
+	return pattern, nil
+}
+
+// exclusiveFlags returns a usage error, if more than one of the flags is set,
+// as they decide the answers of the config questions in different ways.
+func exclusiveFlags(c *cli.Context, names ...string) error {
+	var set []string
+	for _, name := range names {
+		if c.IsSet(name) {
+			set = append(set, "--"+name)
+		}
+	}
+	if len(set) > 1 {
+		return fmt.Errorf("invalid flags, %s can not be used together", strings.Join(set, " and "))
+	}
 	return nil
 }
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/scanner"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+// promptOptionTree has a required text input, and an option shared by the values of its parent.
+func promptOptionTree() models.OptionNode {
+	schemeOption := models.NewOption("Scheme", "", "SCHEME", models.TypeSelector)
+	exportMethodOption := models.NewOption("Export method", "", "EXPORT_METHOD", models.TypeSelector)
+	moduleOption := models.NewOption("Module", "", "MODULE", models.TypeUserInput)
+
+	schemeOption.AddOption("App", exportMethodOption)
+	schemeOption.AddOption("AppTests", exportMethodOption)
+	exportMethodOption.AddOption("app-store", moduleOption)
+	exportMethodOption.AddOption("development", moduleOption)
+	moduleOption.AddConfig("", models.NewConfigOption("ios-config", nil))
+
+	return *schemeOption
+}
+
+func promptScanResult() models.ScanResultModel {
+	return models.ScanResultModel{ScannerToOptionRoot: map[string]models.OptionNode{"ios": promptOptionTree()}}
+}
+
+// askScripted walks the option tree of the test platform with the scripted input lines.
+func askScripted(t *testing.T, script string) (string, []scanner.OptionAnswer, string) {
+	var out bytes.Buffer
+	selection, err := scanner.NewPrompt(strings.NewReader(script), &out, false).AskForSelection(promptScanResult())
+	require.NoError(t, err, out.String())
+	return selection.ConfigName, selection.Answers, out.String()
+}
+
+func Test_askForSelection(t *testing.T) {
+	t.Log("an invalid option number or a missing value is asked again")
+	{
+		configName, answers, out := askScripted(t, "x\n5\n2\n\n\napp\n\n")
+		require.Equal(t, "ios-config", configName)
+		require.Equal(t, []scanner.OptionAnswer{
+			{Title: "Scheme", EnvKey: "SCHEME", Value: "AppTests"},
+			{Title: "Export method", EnvKey: "EXPORT_METHOD", Value: "app-store"},
+			{Title: "Module", EnvKey: "MODULE", Value: "app"},
+		}, answers)
+		require.Contains(t, out, "Invalid option number (x), pick a number from 1-2")
+		require.Contains(t, out, "Invalid option number (5), pick a number from 1-2")
+		require.Contains(t, out, "A value must be specified")
+	}
+
+	t.Log("going back asks the previous question again, defaulting to its previous value")
+	{
+		configName, answers, out := askScripted(t, "2\n3\n\n2\nmobile\n\n")
+		require.Equal(t, "ios-config", configName)
+		require.Equal(t, []scanner.OptionAnswer{
+			{Title: "Scheme", EnvKey: "SCHEME", Value: "AppTests"},
+			{Title: "Export method", EnvKey: "EXPORT_METHOD", Value: "development"},
+			{Title: "Module", EnvKey: "MODULE", Value: "mobile"},
+		}, answers)
+		require.Contains(t, out, "[3] : <go back>")
+		require.Contains(t, out, "[2] : AppTests (default)")
+	}
+
+	t.Log("going back from a text input asks the previous selector again")
+	{
+		_, answers, _ := askScripted(t, "1\n1\n<\n2\napp\n\n")
+		require.Equal(t, []scanner.OptionAnswer{
+			{Title: "Scheme", EnvKey: "SCHEME", Value: "App"},
+			{Title: "Export method", EnvKey: "EXPORT_METHOD", Value: "development"},
+			{Title: "Module", EnvKey: "MODULE", Value: "app"},
+		}, answers)
+	}
+
+	t.Log("a value selected for review is edited, the following questions are asked again")
+	{
+		_, answers, out := askScripted(t, "1\n1\napp\n2\n2\n\n\n")
+		require.Equal(t, []scanner.OptionAnswer{
+			{Title: "Scheme", EnvKey: "SCHEME", Value: "App"},
+			{Title: "Export method", EnvKey: "EXPORT_METHOD", Value: "development"},
+			{Title: "Module", EnvKey: "MODULE", Value: "app"},
+		}, answers)
+		require.Contains(t, out, "[1] : SCHEME: App")
+		require.Contains(t, out, "[2] : EXPORT_METHOD: app-store")
+		require.Contains(t, out, "[4] : <done> (default)")
+	}
+
+	t.Log("the end of the input fails the wizard")
+	{
+		var out bytes.Buffer
+		_, err := scanner.NewPrompt(strings.NewReader("x\n"), &out, false).AskForSelection(promptScanResult())
+		require.EqualError(t, err, "Failed to ask for value, error: EOF")
+	}
+}
+
+func Test_matchValues(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		values []string
+		filter string
+		want   []string
+	}{
+		{name: "the values in their order without a filter", values: []string{"App", "AppTests", "Widget"}, filter: "", want: []string{"App", "AppTests", "Widget"}},
+		{name: "the contiguous matches before the scattered ones", values: []string{"TimeSheets", "AppTests", "Widget"}, filter: "tests", want: []string{"AppTests", "TimeSheets"}},
+		{name: "the scattered matches in their order", values: []string{"AppStaging", "Staging", "Widget"}, filter: "stg", want: []string{"AppStaging", "Staging"}},
+		{name: "case insensitive", values: []string{"MyApp-Staging"}, filter: "MAS", want: []string{"MyApp-Staging"}},
+		{name: "the characters in a different order", values: []string{"MyApp-Staging"}, filter: "gnits", want: nil},
+		{name: "a filter longer than the value", values: []string{"MyApp"}, filter: "MyApp-Staging", want: nil},
+		{name: "non ascii characters", values: []string{"Ünnep"}, filter: "ünp", want: []string{"Ünnep"}},
+	} {
+		require.Equal(t, tt.want, scanner.MatchValues(tt.values, tt.filter), tt.name)
+	}
+}
+
+func Test_optionConfigs(t *testing.T) {
+	tree := promptOptionTree()
+	exportMethodOption := tree.ChildOptionMap["App"]
+
+	otherModuleOption := models.NewOption("Module", "", "MODULE", models.TypeUserInput)
+	otherModuleOption.AddConfig("", models.NewConfigOption("ios-app-store-config", nil))
+	exportMethodOption.AddOption("app-store", otherModuleOption)
+
+	for _, tt := range []struct {
+		name   string
+		option *models.OptionNode
+		want   []string
+	}{
+		{name: "nil option", option: nil, want: nil},
+		{name: "config option", option: models.NewConfigOption("ios-config", nil), want: []string{"ios-config"}},
+		{name: "single config", option: exportMethodOption.ChildOptionMap["development"], want: []string{"ios-config"}},
+		{name: "distinct configs sorted", option: &tree, want: []string{"ios-app-store-config", "ios-config"}},
+	} {
+		require.Equal(t, tt.want, tt.option.Configs(), tt.name)
+	}
+}
+
+func Test_configStepsPreview(t *testing.T) {
+	preview := scanner.ConfigStepsPreview(models.BitriseConfigMap{
+		"ios-config": `format_version: "8"
+workflows:
+  primary:
+    steps:
+    - activate-ssh-key@4: {}
+    - xcode-test@2: {}
+  deploy:
+    steps:
+    - xcode-archive@3: {}
+`,
+		"invalid-config": "workflows: [",
+	})
+
+	for _, tt := range []struct {
+		configName string
+		want       []string
+	}{
+		{configName: "ios-config", want: []string{
+			"Config: ios-config",
+			"  deploy:",
+			"    - xcode-archive@3",
+			"  primary:",
+			"    - activate-ssh-key@4",
+			"    - xcode-test@2",
+		}},
+		{configName: "invalid-config", want: nil},
+		{configName: "missing-config", want: nil},
+	} {
+		require.Equal(t, tt.want, preview(tt.configName), tt.configName)
+	}
+}
+
+func Test_exclusiveFlags(t *testing.T) {
+	newContext := func(args ...string) *cli.Context {
+		set := flag.NewFlagSet("init", flag.ContinueOnError)
+		set.Bool("minimal", false, "")
+		set.String("answers", "", "")
+		set.String("replay", "", "")
+		set.Bool("web", false, "")
+		require.NoError(t, set.Parse(args))
+		return cli.NewContext(nil, set, nil)
+	}
+
+	t.Log("a single flag is valid")
+	{
+		require.NoError(t, exclusiveFlags(newContext("--answers", "answers.yml"), "minimal", "answers"))
+		require.NoError(t, exclusiveFlags(newContext(), "minimal", "answers"))
+	}
+
+	t.Log("the flags set together are rejected")
+	{
+		err := exclusiveFlags(newContext("--minimal", "--answers", "answers.yml"), "minimal", "answers")
+		require.EqualError(t, err, "invalid flags, --minimal and --answers can not be used together")
+
+		err = exclusiveFlags(newContext("--answers", "answers.yml", "--replay", "init-replay.yml"), "minimal", "answers", "replay")
+		require.EqualError(t, err, "invalid flags, --answers and --replay can not be used together")
+
+		err = exclusiveFlags(newContext("--replay", "init-replay.yml", "--web"), "minimal", "answers", "replay", "web")
+		require.EqualError(t, err, "invalid flags, --replay and --web can not be used together")
+	}
+}
@@ -0,0 +1,523 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/scanner"
+	"github.com/bitrise-io/bitrise-init/scanners/android"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_scanAndroidModules(t *testing.T) {
+	t.Log("the application modules of the settings script are offered, with their projectDir")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle": `include ':mobile', ':lib'
+include(':apps:phone')
+include ':wear'
+// include ':legacy'
+project(':wear').projectDir = new File(rootDir, 'watch/wear')
+`,
+			"build.gradle":                "",
+			"gradlew":                     "",
+			"mobile/build.gradle":         "plugins {\n  id 'com.android.application'\n}\n",
+			"lib/build.gradle":            "apply plugin: 'com.android.library'\n",
+			"apps/phone/build.gradle.kts": "plugins {\n  id(\"com.android.application\")\n}\n",
+			"watch/wear/build.gradle":     "apply plugin: \"com.android.application\"\n",
+			"legacy/build.gradle":         "apply plugin: 'com.android.application'\n",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, []string{"android"}, detectedScanners(result))
+
+		projectLocation := result.ScannerToOptionRoot["android"]
+		moduleOption := projectLocation.ChildOptionMap["."]
+		require.Equal(t, models.TypeSelector, moduleOption.Type)
+		require.Equal(t, []string{"apps:phone", "mobile", "wear"}, moduleOption.GetValues())
+
+		configNames := map[string][]string{}
+		for module, variantOption := range moduleOption.ChildOptionMap {
+			configNames[module] = variantOption.Configs()
+		}
+		require.Equal(t, map[string][]string{
+			"mobile":     {"android-aab-config", "android-apk-aab-config", "android-config"},
+			"apps:phone": {"android-apps-phone-kts-aab-config", "android-apps-phone-kts-apk-aab-config", "android-apps-phone-kts-config"},
+			"wear":       {"android-watch-wear-aab-config", "android-watch-wear-apk-aab-config", "android-watch-wear-config"},
+		}, configNames)
+
+		configs := result.ScannerToBitriseConfigMap["android"]
+		require.Equal(t, 9, len(configs))
+		require.Contains(t, configs["android-config"], "build_gradle_path: $PROJECT_LOCATION/$MODULE/build.gradle")
+		require.Contains(t, configs["android-apps-phone-kts-config"], "build_gradle_path: $PROJECT_LOCATION/apps/phone/build.gradle.kts")
+		require.Contains(t, configs["android-watch-wear-config"], "build_gradle_path: $PROJECT_LOCATION/watch/wear/build.gradle")
+
+		require.Equal(t, []string{"lib/build.gradle: module lib does not apply the com.android.application plugin"}, result.ScannerToExplanation["android"].Skipped)
+	}
+
+	t.Log("the module is asked, if no application module is found")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle":  "",
+			"build.gradle":     "",
+			"gradlew":          "",
+			"app/build.gradle": "",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		projectLocation := result.ScannerToOptionRoot["android"]
+		moduleOption := projectLocation.ChildOptionMap["."]
+		require.Equal(t, models.TypeUserInput, moduleOption.Type)
+		require.Equal(t, []string{"app"}, moduleOption.GetValues())
+		_, ok := result.ScannerToBitriseConfigMap["android"]["android-config"]
+		require.True(t, ok)
+	}
+}
+
+func Test_scanAndroidVariants(t *testing.T) {
+	t.Log("the variants combine the flavors of every dimension with the build types")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle": "include ':app'\n",
+			"build.gradle":    "",
+			"gradlew":         "",
+			"app/build.gradle": `plugins {
+    id 'com.android.application'
+}
+
+android {
+    flavorDimensions "tier", "env"
+    buildTypes {
+        release {
+            minifyEnabled true
+        }
+        staging {
+            initWith debug
+        }
+    }
+    productFlavors {
+        free { dimension "tier" }
+        paid { dimension "tier" }
+        dev { dimension "env" }
+        prod { dimension = "env" }
+    }
+}
+`,
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		projectLocation := result.ScannerToOptionRoot["android"]
+		variantOption := projectLocation.ChildOptionMap["."].ChildOptionMap["app"]
+		require.Equal(t, models.TypeOptionalSelector, variantOption.Type)
+		require.Equal(t, []string{
+			"freeDevDebug", "freeDevRelease", "freeDevStaging",
+			"freeProdDebug", "freeProdRelease", "freeProdStaging",
+			"paidDevDebug", "paidDevRelease", "paidDevStaging",
+			"paidProdDebug", "paidProdRelease", "paidProdStaging",
+		}, variantOption.GetValues())
+		require.Equal(t, "freeDevRelease", variantOption.DefaultValue)
+
+		testVariantOption := variantOption.ChildOptionMap["paidProdRelease"]
+		require.Equal(t, android.TestVariantInputEnvKey, testVariantOption.EnvKey)
+		require.Equal(t, []string{"paidProdDebug"}, testVariantOption.GetValues())
+		require.Equal(t, "paidProdDebug", testVariantOption.DefaultValue)
+		require.Equal(t, []string{"freeDevDebug"}, variantOption.ChildOptionMap["freeDevStaging"].GetValues())
+
+		config := result.ScannerToBitriseConfigMap["android"]["android-config"]
+		require.Contains(t, config, "variant: $TEST_VARIANT")
+		require.Contains(t, config, "variant: $VARIANT")
+	}
+
+	t.Log("the Kotlin DSL declares the build types and the flavors by name")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle.kts": "include(\":app\")\n",
+			"build.gradle.kts":    "",
+			"gradlew":             "",
+			"app/build.gradle.kts": `plugins {
+    id("com.android.application")
+}
+
+android {
+    buildTypes {
+        getByName("release") {
+            isMinifyEnabled = true
+        }
+        create("beta") {
+            initWith(getByName("debug"))
+        }
+    }
+    flavorDimensions += listOf("tier")
+    productFlavors {
+        create("free") { dimension = "tier" }
+        create("paid")
+    }
+}
+`,
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		projectLocation := result.ScannerToOptionRoot["android"]
+		variantOption := projectLocation.ChildOptionMap["."].ChildOptionMap["app"]
+		require.Equal(t, []string{"freeDebug", "freeRelease", "freeBeta", "paidDebug", "paidRelease", "paidBeta"}, variantOption.GetValues())
+		require.Equal(t, "freeRelease", variantOption.DefaultValue)
+		require.Equal(t, "freeDebug", variantOption.ChildOptionMap["freeRelease"].DefaultValue)
+	}
+
+	t.Log("a module without flavors has the default build types")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle":  "include ':app'\n",
+			"build.gradle":     "",
+			"gradlew":          "",
+			"app/build.gradle": "apply plugin: 'com.android.application'\n",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		projectLocation := result.ScannerToOptionRoot["android"]
+		variantOption := projectLocation.ChildOptionMap["."].ChildOptionMap["app"]
+		require.Equal(t, []string{"debug", "release"}, variantOption.GetValues())
+		require.Equal(t, "release", variantOption.DefaultValue)
+		require.Equal(t, "debug", variantOption.ChildOptionMap["release"].DefaultValue)
+	}
+}
+
+func Test_scanAndroidSigning(t *testing.T) {
+	t.Log("the artifact is offered, the signing config reading env vars gets the keystore secrets")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle": "include ':app'\n",
+			"build.gradle":    "",
+			"gradlew":         "",
+			"app/build.gradle": `plugins {
+    id 'com.android.application'
+}
+
+android {
+    signingConfigs {
+        release {
+            storeFile file(System.getenv("KEYSTORE_PATH"))
+            storePassword System.getenv("KEYSTORE_PASSWORD")
+            keyAlias System.env.KEY_ALIAS
+            keyPassword System.getenv('KEY_PASSWORD')
+        }
+    }
+    buildTypes {
+        release {
+            signingConfig signingConfigs.release
+        }
+    }
+}
+`,
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		projectLocation := result.ScannerToOptionRoot["android"]
+		testVariantOption := projectLocation.ChildOptionMap["."].ChildOptionMap["app"].ChildOptionMap["release"]
+		javaVersionOption := testVariantOption.ChildOptionMap["debug"]
+		require.Equal(t, models.TypeOptionalUserInput, javaVersionOption.Type)
+		artifactOption := javaVersionOption.ChildOptionMap[""]
+		require.Equal(t, android.BuildArtifactInputTitle, artifactOption.Title)
+		require.Equal(t, []string{"apk", "aab", "both"}, artifactOption.SortedValues())
+		require.Equal(t, "apk", artifactOption.DefaultValue)
+		require.Equal(t, "android-gradle-signing-config", artifactOption.ChildOptionMap["apk"].Config)
+		require.Equal(t, "android-gradle-signing-aab-config", artifactOption.ChildOptionMap["aab"].Config)
+		require.Equal(t, "android-gradle-signing-apk-aab-config", artifactOption.ChildOptionMap["both"].Config)
+
+		configs := result.ScannerToBitriseConfigMap["android"]
+		require.Contains(t, configs["android-gradle-signing-config"], "build_type: apk")
+		require.NotContains(t, configs["android-gradle-signing-config"], "build_type: aab")
+		require.Contains(t, configs["android-gradle-signing-aab-config"], "build_type: aab")
+		require.Contains(t, configs["android-gradle-signing-apk-aab-config"], "build_type: apk")
+		require.Contains(t, configs["android-gradle-signing-apk-aab-config"], "build_type: aab")
+		for _, config := range configs {
+			require.Contains(t, config, "- KEYSTORE_PATH: $HOME/keystores/release.keystore")
+			require.Contains(t, config, "destination: $KEYSTORE_PATH")
+			require.NotContains(t, config, "sign-apk")
+		}
+
+		secrets := []string{"KEYSTORE_PASSWORD", "KEY_ALIAS", "KEY_PASSWORD", "BITRISEIO_ANDROID_KEYSTORE_URL"}
+		require.Equal(t, models.ConfigSecrets{
+			"android-gradle-signing-config":         secrets,
+			"android-gradle-signing-aab-config":     secrets,
+			"android-gradle-signing-apk-aab-config": secrets,
+		}, result.ScannerToConfigSecrets["android"])
+
+		selection := scanner.Selection{Platform: "android", ConfigName: "android-gradle-signing-aab-config"}
+		envs := scanner.BuildSecrets(result, selection).Envs
+		require.Equal(t, 4, len(envs))
+		require.Equal(t, "", envs[0]["KEYSTORE_PASSWORD"])
+	}
+
+	t.Log("the signing config of the release build type is used, the Kotlin DSL reads the env vars with providers")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle.kts": "include(\":app\")\n",
+			"build.gradle.kts":    "",
+			"gradlew":             "",
+			"app/build.gradle.kts": `plugins {
+    id("com.android.application")
+}
+
+android {
+    signingConfigs {
+        create("staging") {
+            storePassword = System.getenv("STAGING_PASSWORD")
+        }
+        create("upload") {
+            storeFile = file(System.getenv("HOME") + "/upload.jks")
+            storePassword = providers.environmentVariable("UPLOAD_STORE_PASSWORD").get()
+            keyAlias = "upload"
+            keyPassword = providers.environmentVariable("UPLOAD_STORE_PASSWORD").get()
+        }
+    }
+    buildTypes {
+        getByName("release") {
+            signingConfig = signingConfigs.getByName("upload")
+        }
+    }
+}
+`,
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, models.ConfigSecrets{
+			"android-kts-gradle-signing-config":         {"UPLOAD_STORE_PASSWORD"},
+			"android-kts-gradle-signing-aab-config":     {"UPLOAD_STORE_PASSWORD"},
+			"android-kts-gradle-signing-apk-aab-config": {"UPLOAD_STORE_PASSWORD"},
+		}, result.ScannerToConfigSecrets["android"])
+
+		config := result.ScannerToBitriseConfigMap["android"]["android-kts-gradle-signing-config"]
+		require.NotContains(t, config, "file-downloader")
+		require.Contains(t, config, "**UPLOAD_STORE_PASSWORD**")
+	}
+
+	t.Log("the sign-apk step signs the artifact, if the signing config does not read env vars")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle": "include ':app'\n",
+			"build.gradle":    "",
+			"gradlew":         "",
+			"app/build.gradle": `apply plugin: 'com.android.application'
+
+android {
+    signingConfigs {
+        release {
+            storeFile file(keystoreProperties['storeFile'])
+            storePassword keystoreProperties['storePassword']
+        }
+    }
+}
+`,
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Nil(t, result.ScannerToConfigSecrets)
+		configs := result.ScannerToBitriseConfigMap["android"]
+		require.Equal(t, 3, len(configs))
+		require.Contains(t, configs["android-config"], "sign-apk")
+		require.Equal(t, envmanModels.EnvsSerializeModel{}, scanner.BuildSecrets(result, scanner.Selection{Platform: "android", ConfigName: "android-config"}))
+	}
+}
+
+func Test_androidJavaRequirementMaxJDK(t *testing.T) {
+	for _, tt := range []struct {
+		gradleVersion string
+		want          int
+	}{
+		{gradleVersion: "", want: 0},
+		{gradleVersion: "4.10.3", want: 8},
+		{gradleVersion: "5.0", want: 11},
+		{gradleVersion: "5.3.1", want: 11},
+		{gradleVersion: "5.4", want: 12},
+		{gradleVersion: "5.6.4", want: 12},
+		{gradleVersion: "6.0", want: 13},
+		{gradleVersion: "6.2.2", want: 13},
+		{gradleVersion: "6.3", want: 14},
+		{gradleVersion: "6.6.1", want: 14},
+		{gradleVersion: "6.7", want: 15},
+		{gradleVersion: "6.9.4", want: 15},
+		{gradleVersion: "7.0", want: 16},
+		{gradleVersion: "7.2", want: 16},
+		{gradleVersion: "7.3", want: 17},
+		{gradleVersion: "7.4.2", want: 17},
+		{gradleVersion: "7.5", want: 18},
+		{gradleVersion: "7.5.1", want: 18},
+		{gradleVersion: "7.6", want: 19},
+		{gradleVersion: "8.2.1", want: 19},
+		{gradleVersion: "8.3", want: 20},
+		{gradleVersion: "8.4", want: 20},
+		{gradleVersion: "8.5", want: 21},
+		{gradleVersion: "8.14", want: 24},
+		{gradleVersion: "9.1.0", want: 25},
+	} {
+		require.Equal(t, tt.want, android.JavaRequirement{GradleVersion: tt.gradleVersion}.MaxJDK(), tt.gradleVersion)
+	}
+}
+
+func Test_scanAndroidJava(t *testing.T) {
+	javaVersionOption := func(result models.ScanResultModel, variant, testVariant string) *models.OptionNode {
+		projectLocation := result.ScannerToOptionRoot["android"]
+		return projectLocation.ChildOptionMap["."].ChildOptionMap["app"].ChildOptionMap[variant].ChildOptionMap[testVariant]
+	}
+
+	t.Log("the JDK required by the Android Gradle plugin is recommended")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle": "include ':app'\n",
+			"build.gradle":    "plugins {\n    id 'com.android.application' version '8.2.0' apply false\n}\n",
+			"gradlew":         "",
+			"gradle/wrapper/gradle-wrapper.properties": "distributionUrl=https\\://services.gradle.org/distributions/gradle-8.5-bin.zip\n",
+			"app/build.gradle":                         "apply plugin: 'com.android.application'\n",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		option := javaVersionOption(result, "release", "debug")
+		require.Equal(t, android.JavaVersionInputEnvKey, option.EnvKey)
+		require.Equal(t, models.TypeOptionalSelector, option.Type)
+		require.Equal(t, []string{"17"}, option.GetValues())
+		require.Equal(t, "17", option.DefaultValue)
+		require.Empty(t, result.ScannerToWarningsWithRecommendations["android"])
+
+		config := result.ScannerToBitriseConfigMap["android"]["android-config"]
+		require.Contains(t, config, "set-java-version@1")
+		require.Contains(t, config, "set_java_version: $JAVA_VERSION")
+	}
+
+	t.Log("the declared JDK is recommended, if it is compatible, the toolchain raises the JDK")
+	{
+		searchDir := createProject(t, map[string]string{
+			".tool-versions":  "nodejs 20.1.0\njava temurin-21.0.1+12\n",
+			"settings.gradle": "include ':app'\n",
+			"build.gradle":    "buildscript {\n    dependencies {\n        classpath 'com.android.tools.build:gradle:7.4.2'\n    }\n}\n",
+			"gradlew":         "",
+			"gradle/wrapper/gradle-wrapper.properties": "distributionUrl=https\\://services.gradle.org/distributions/gradle-8.5-all.zip\n",
+			"app/build.gradle":                         "apply plugin: 'com.android.application'\n",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, []string{"21"}, javaVersionOption(result, "release", "debug").GetValues())
+		require.Empty(t, result.ScannerToWarningsWithRecommendations["android"])
+
+		require.NoError(t, os.Remove(filepath.Join(searchDir, ".tool-versions")))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(searchDir, "app", "build.gradle"), []byte(`apply plugin: 'com.android.application'
+
+java {
+    toolchain {
+        languageVersion = JavaLanguageVersion.of(17)
+    }
+}
+`), 0644))
+		result = scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, []string{"17"}, javaVersionOption(result, "release", "debug").GetValues())
+	}
+
+	t.Log("the incompatible versions are warned about, the version catalog declares the Android Gradle plugin")
+	{
+		searchDir := createProject(t, map[string]string{
+			".java-version":   "11\n",
+			"settings.gradle": "include ':app'\n",
+			"build.gradle":    "plugins {\n    alias(libs.plugins.android.application) apply false\n}\n",
+			"gradlew":         "",
+			"gradle/libs.versions.toml": `[versions]
+agp = "8.1.4"
+
+[plugins]
+android-application = { id = "com.android.application", version.ref = "agp" }
+`,
+			"gradle/wrapper/gradle-wrapper.properties": "distributionUrl=https\\://services.gradle.org/distributions/gradle-7.2-bin.zip\n",
+			"app/build.gradle":                         "apply plugin: 'com.android.application'\n",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, []string{"17"}, javaVersionOption(result, "release", "debug").GetValues())
+		var warnings []string
+		for _, warning := range result.ScannerToWarningsWithRecommendations["android"] {
+			warnings = append(warnings, warning.Error)
+		}
+		require.Equal(t, []string{
+			"the Android Gradle plugin 8.1.4 requires JDK 17, but Gradle 7.2 of the wrapper runs on JDK 16 at most, update the Gradle wrapper, the location of the Android project is: " + searchDir,
+			".java-version declares JDK 11, but the build requires JDK 17 or later (Android Gradle plugin 8.1.4, Gradle 7.2), the location of the Android project is: " + searchDir,
+		}, warnings)
+	}
+
+	t.Log("the JDK is asked, if it can not be inferred")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle":  "include ':app'\n",
+			"build.gradle":     "",
+			"gradlew":          "",
+			"app/build.gradle": "apply plugin: 'com.android.application'\n",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		option := javaVersionOption(result, "release", "debug")
+		require.Equal(t, models.TypeOptionalUserInput, option.Type)
+		require.Equal(t, "", option.DefaultValue)
+	}
+}
+
+func Test_scanAndroidVersionCatalog(t *testing.T) {
+	t.Log("the plugins applied by their version catalog alias are resolved")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle.kts": "include(\":app\", \":lib\", \":tv\")\n",
+			"build.gradle.kts":    "plugins {\n    alias(libs.plugins.android.application) apply false\n}\n",
+			"gradlew":             "",
+			"gradle/libs.versions.toml": `[versions]
+agp = "8.2.0" # the Android Gradle plugin
+
+[plugins]
+android-application = { id = "com.android.application", version.ref = "agp" }
+android_library = "com.android.library:8.2.0"
+tv.id = "com.android.application"
+tv.version = { strictly = "8.2.0" }
+`,
+			"gradle/wrapper/gradle-wrapper.properties": "distributionUrl=https\\://services.gradle.org/distributions/gradle-8.5-bin.zip\n",
+			"app/build.gradle.kts":                     "plugins {\n    alias(libs.plugins.android.application)\n}\n",
+			"lib/build.gradle.kts":                     "plugins {\n    alias(libs.plugins.android.library)\n}\n",
+			"tv/build.gradle.kts":                      "plugins {\n    alias( libs.plugins.tv )\n}\n",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, []string{"android"}, detectedScanners(result))
+
+		moduleOption := result.ScannerToOptionRoot["android"].ChildOptionMap["."]
+		require.Equal(t, []string{"app", "tv"}, moduleOption.GetValues())
+
+		javaVersionOption := moduleOption.ChildOptionMap["app"].ChildOptionMap["release"].ChildOptionMap["debug"]
+		require.Equal(t, []string{"17"}, javaVersionOption.GetValues())
+	}
+
+	t.Log("the Android Gradle plugin version of the library notation is used, if the plugins declare none")
+	{
+		searchDir := createProject(t, map[string]string{
+			"settings.gradle": "include ':app'\n",
+			"build.gradle":    "buildscript {\n    dependencies {\n        classpath libs.android.gradle.plugin\n    }\n}\n",
+			"gradlew":         "",
+			"gradle/libs.versions.toml": `[versions]
+agp = "7.4.2"
+
+[libraries]
+android-gradle-plugin = { group = "com.android.tools.build", name = "gradle", version.ref = "agp" }
+
+[plugins]
+android-application = { id = "com.android.application" }
+`,
+			"gradle/wrapper/gradle-wrapper.properties": "distributionUrl=https\\://services.gradle.org/distributions/gradle-8.5-bin.zip\n",
+			"app/build.gradle":                         "plugins {\n    alias(libs.plugins.android.application)\n}\n",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		moduleOption := result.ScannerToOptionRoot["android"].ChildOptionMap["."]
+		require.Equal(t, []string{"app"}, moduleOption.GetValues())
+
+		javaVersionOption := moduleOption.ChildOptionMap["app"].ChildOptionMap["release"].ChildOptionMap["debug"]
+		require.Equal(t, []string{"11"}, javaVersionOption.GetValues())
+	}
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/scanner"
+)
+
+// platformAnswerKey is the answers file key used to select the platform (scanner name),
+// it is required when more than one platform is detected.
+const platformAnswerKey = "platform"
+
+// answers maps an option's EnvKey or Title to the value selected for it.
+type answers map[string]string
+
+// answerIssue describes a question which could not be answered from the answers file.
+type answerIssue struct {
+	question string
+	value    string
+	allowed  []string
+}
+
+func (issue answerIssue) String() string {
+	s := fmt.Sprintf("- %s: ", issue.question)
+	if issue.value == "" {
+		s += "no answer provided"
+	} else {
+		s += fmt.Sprintf("invalid answer (%s)", issue.value)
+	}
+	if len(issue.allowed) > 0 {
+		s += fmt.Sprintf(", allowed values: %s", strings.Join(issue.allowed, ", "))
+	}
+	return s
+}
+
+// answersError is returned if any question could not be answered, it lists every issue found.
+type answersError struct {
+	issues []answerIssue
+}
+
+func (err answersError) Error() string {
+	lines := []string{fmt.Sprintf("failed to answer %d question(s) from the answers file:", len(err.issues))}
+	for _, issue := range err.issues {
+		lines = append(lines, issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func readAnswers(pth string) (answers, error) {
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file (%s), error: %s", pth, err)
+	}
+
+	// JSON is a subset of YAML, so the yaml package parses both formats
+	var a answers
+	if err := yaml.Unmarshal(content, &a); err != nil {
+		return nil, fmt.Errorf("failed to parse answers file (%s), error: %s", pth, err)
+	}
+	if a == nil {
+		a = answers{}
+	}
+	return a, nil
+}
+
+// lookup returns the answer for the given option, EnvKey takes precedence over Title.
+func (a answers) lookup(opt models.OptionNode) (string, bool) {
+	if opt.EnvKey != "" {
+		if value, ok := a[opt.EnvKey]; ok {
+			return value, true
+		}
+	}
+	value, ok := a[opt.Title]
+	return value, ok
+}
+
+func optionQuestion(opt models.OptionNode) string {
+	if opt.EnvKey != "" {
+		return fmt.Sprintf("%s (%s)", opt.Title, opt.EnvKey)
+	}
+	return opt.Title
+}
+
+// answerOptions walks the option tree and answers every question from the answers,
+// it returns the selected config name and the answers on the path, or an answersError listing every issue found.
+func answerOptions(options models.OptionNode, a answers) (string, []scanner.OptionAnswer, error) {
+	var issues []answerIssue
+	selected := []scanner.OptionAnswer{}
+
+	opt := &options
+	for opt != nil && !opt.IsConfigOption() {
+		if !opt.IsValueOption() {
+			break
+		}
+
+		values := opt.SortedValues()
+		value, answered := a.lookup(*opt)
+
+		// the first child is followed if the answer is a custom value, or missing,
+		// in the later case to collect the issues of the rest of the questions
+		var firstChild *models.OptionNode
+		if len(values) > 0 {
+			firstChild = opt.ChildOptionMap[values[0]]
+		}
+
+		next, ok := opt.ChildOptionMap[value]
+		switch opt.Type {
+		case models.TypeSelector, models.TypeOptionalSelector:
+			if !answered && len(values) == 1 {
+				value, next, answered, ok = values[0], firstChild, true, true
+			}
+			if !answered {
+				issues = append(issues, answerIssue{question: optionQuestion(*opt), allowed: values})
+			} else if !ok && opt.Type == models.TypeSelector {
+				issues = append(issues, answerIssue{question: optionQuestion(*opt), value: value, allowed: values})
+			}
+		case models.TypeUserInput, models.TypeOptionalUserInput:
+			// like the interactive prompt, a missing answer defaults to the recommended value, like the module of the option
+			if strings.TrimSpace(value) == "" {
+				value = opt.RecommendedValue()
+				next, ok = opt.ChildOptionMap[value]
+			}
+			if opt.Type == models.TypeUserInput && strings.TrimSpace(value) == "" {
+				issues = append(issues, answerIssue{question: optionQuestion(*opt)})
+			}
+		default:
+			return "", nil, fmt.Errorf("invalid input type (%s) for option: %s", opt.Type, opt.Title)
+		}
+
+		if !ok {
+			next = firstChild
+		}
+		if next == nil {
+			break
+		}
+
+		selected = append(selected, scanner.OptionAnswer{
+			Title:  opt.Title,
+			EnvKey: opt.EnvKey,
+			Value:  strings.TrimSpace(value),
+		})
+		opt = next
+	}
+
+	if len(issues) > 0 {
+		return "", nil, answersError{issues: issues}
+	}
+	if opt == nil || !opt.IsConfigOption() {
+		return "", nil, errors.New("no config selected")
+	}
+	return opt.Config, selected, nil
+}
+
+// answerSelection selects the platform and walks its option tree based on the answers,
+// instead of asking the user.
+func answerSelection(scanResult models.ScanResultModel, a answers) (scanner.Selection, error) {
+	var platforms []string
+	for platform := range scanResult.ScannerToOptionRoot {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	if len(platforms) == 0 {
+		return scanner.Selection{}, errors.New("no platform detected")
+	}
+
+	// the platform answer is validated even if a single platform is detected, it is required only if more are detected
+	platform, ok := a[platformAnswerKey]
+	if !ok && len(platforms) == 1 {
+		platform = platforms[0]
+	} else if !ok {
+		return scanner.Selection{}, answersError{issues: []answerIssue{{question: platformAnswerKey, allowed: platforms}}}
+	} else if _, detected := scanResult.ScannerToOptionRoot[platform]; !detected {
+		return scanner.Selection{}, answersError{issues: []answerIssue{{question: platformAnswerKey, value: platform, allowed: platforms}}}
+	}
+
+	configName, selected, err := answerOptions(scanResult.ScannerToOptionRoot[platform], a)
+	if err != nil {
+		return scanner.Selection{}, err
+	}
+
+	return scanner.Selection{
+		Platform:   platform,
+		Answers:    selected,
+		ConfigName: configName,
+	}, nil
+}
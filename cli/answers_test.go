@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/scanner"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptionTree() models.OptionNode {
+	projectOption := models.NewOption("Project path", "", "PROJECT_PATH", models.TypeSelector)
+	schemeOption := models.NewOption("Scheme", "", "SCHEME", models.TypeSelector)
+	exportMethodOption := models.NewOption("Export method", "", "EXPORT_METHOD", models.TypeSelector)
+	variantOption := models.NewOption("Variant", "", "VARIANT", models.TypeOptionalUserInput)
+
+	projectOption.AddOption("App.xcodeproj", schemeOption)
+	schemeOption.AddOption("App", exportMethodOption)
+	schemeOption.AddOption("AppTests", exportMethodOption)
+	exportMethodOption.AddOption("app-store", variantOption)
+	exportMethodOption.AddOption("development", variantOption)
+	variantOption.AddConfig("", models.NewConfigOption("ios-config", nil))
+
+	return *projectOption
+}
+
+func Test_answerOptions(t *testing.T) {
+	t.Log("answers keyed by env key and title")
+	{
+		configName, selected, err := answerOptions(testOptionTree(), answers{
+			"SCHEME":        "App",
+			"Export method": "development",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "ios-config", configName)
+		require.Equal(t, []envmanModels.EnvironmentItemModel{
+			{"PROJECT_PATH": "App.xcodeproj"},
+			{"SCHEME": "App"},
+			{"EXPORT_METHOD": "development"},
+			{"VARIANT": ""},
+		}, scanner.Selection{Answers: selected}.AppEnvs())
+	}
+
+	t.Log("reports every unanswered and invalid question")
+	{
+		_, _, err := answerOptions(testOptionTree(), answers{
+			"SCHEME": "Unknown",
+		})
+		require.EqualError(t, err, `failed to answer 2 question(s) from the answers file:
+- Scheme (SCHEME): invalid answer (Unknown), allowed values: App, AppTests
+- Export method (EXPORT_METHOD): no answer provided, allowed values: app-store, development`)
+	}
+
+	t.Log("a missing user input defaults to the value of the option, like the interactive prompt")
+	{
+		moduleOption := models.NewOption("Module", "", "MODULE", models.TypeUserInput)
+		moduleOption.AddConfig("app", models.NewConfigOption("android-config", nil))
+
+		configName, selected, err := answerOptions(*moduleOption, answers{})
+		require.NoError(t, err)
+		require.Equal(t, "android-config", configName)
+		require.Equal(t, []scanner.OptionAnswer{{Title: "Module", EnvKey: "MODULE", Value: "app"}}, selected)
+
+		configName, selected, err = answerOptions(*moduleOption, answers{"MODULE": "mobile"})
+		require.NoError(t, err)
+		require.Equal(t, "android-config", configName)
+		require.Equal(t, []scanner.OptionAnswer{{Title: "Module", EnvKey: "MODULE", Value: "mobile"}}, selected)
+	}
+
+	t.Log("requires a value for user input without a default")
+	{
+		moduleOption := models.NewOption("Module", "", "MODULE", models.TypeUserInput)
+		moduleOption.AddConfig("", models.NewConfigOption("android-config", nil))
+
+		_, _, err := answerOptions(*moduleOption, answers{})
+		require.EqualError(t, err, `failed to answer 1 question(s) from the answers file:
+- Module (MODULE): no answer provided`)
+	}
+}
+
+func Test_answerSelection(t *testing.T) {
+	scanResult := models.ScanResultModel{
+		ScannerToOptionRoot: map[string]models.OptionNode{"ios": testOptionTree()},
+	}
+	iosAnswers := answers{"SCHEME": "App", "EXPORT_METHOD": "app-store"}
+
+	t.Log("the platform answer is optional if a single platform is detected")
+	{
+		selection, err := answerSelection(scanResult, iosAnswers)
+		require.NoError(t, err)
+		require.Equal(t, "ios", selection.Platform)
+		require.Equal(t, "ios-config", selection.ConfigName)
+	}
+
+	t.Log("the platform answer has to be the detected platform")
+	{
+		iosAnswers[platformAnswerKey] = "android"
+		_, err := answerSelection(scanResult, iosAnswers)
+		require.EqualError(t, err, `failed to answer 1 question(s) from the answers file:
+- platform: invalid answer (android), allowed values: ios`)
+	}
+}
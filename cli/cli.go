@@ -36,11 +36,188 @@ func Run() {
 
 		return nil
 	}
+	app.Commands = []cli.Command{
+		{
+			Name:  "scan",
+			Usage: "Scan the project and print the scan result (options, configs, warnings and errors)",
+			Action: func(c *cli.Context) error {
+				if err := scan(c); err != nil {
+					log.Fatal(err)
+				}
+
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "directory to scan, defaults to the current directory",
+				},
+				cli.StringFlag{
+					Name:  "output-dir",
+					Usage: "write the scan result and the found app icons into the given directory, instead of printing the result",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Value: "yaml",
+					Usage: "format of the scan result: raw, json or yaml",
+				},
+				cli.StringSliceFlag{
+					Name:  "scanners",
+					Usage: "run only the given scanners, comma separated, like android,fastlane",
+				},
+				cli.StringSliceFlag{
+					Name:  "skip-scanners",
+					Usage: "do not run the given scanners, comma separated, like cordova",
+				},
+				cli.StringSliceFlag{
+					Name:  "include",
+					Usage: "scan only the paths matching the gitignore style pattern, relative to the scanned directory, like apps/, can be repeated",
+				},
+				cli.StringSliceFlag{
+					Name:  "exclude",
+					Usage: "do not scan the paths matching the gitignore style pattern, relative to the scanned directory, like samples/, can be repeated",
+				},
+				cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "time limit of the scan, like 10m, the scanners still running then are reported as failed, 0s means no limit",
+				},
+				cli.StringSliceFlag{
+					Name:  "scanner-timeout",
+					Usage: "time budget of a scanner as NAME=DURATION, like ios=15m, overriding its default budget, can be repeated",
+				},
+				cli.BoolFlag{
+					Name:  "no-cache",
+					Usage: "do not reuse or store the cached scanner outputs, the outputs are cached by the files the scanners read",
+				},
+			},
+		},
+		{
+			Name:  "explain",
+			Usage: "Explain the detection decisions: the files every scanner looked for and found, the candidates it left out, and why it did, or did not detect its platform",
+			Action: func(c *cli.Context) error {
+				if err := explain(c); err != nil {
+					log.Fatal(err)
+				}
+
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "directory to scan, defaults to the current directory",
+				},
+				cli.StringSliceFlag{
+					Name:  "scanners",
+					Usage: "run only the given scanners, comma separated, like android,fastlane",
+				},
+				cli.StringSliceFlag{
+					Name:  "skip-scanners",
+					Usage: "do not run the given scanners, comma separated, like cordova",
+				},
+				cli.StringSliceFlag{
+					Name:  "include",
+					Usage: "scan only the paths matching the gitignore style pattern, relative to the scanned directory, like apps/, can be repeated",
+				},
+				cli.StringSliceFlag{
+					Name:  "exclude",
+					Usage: "do not scan the paths matching the gitignore style pattern, relative to the scanned directory, like samples/, can be repeated",
+				},
+				cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "time limit of the scan, like 10m, the scanners still running then are reported as failed, 0s means no limit",
+				},
+				cli.StringSliceFlag{
+					Name:  "scanner-timeout",
+					Usage: "time budget of a scanner as NAME=DURATION, like ios=15m, overriding its default budget, can be repeated",
+				},
+			},
+		},
+	}
 	app.Flags = []cli.Flag{
 		cli.BoolFlag{
 			Name:  "minimal",
 			Usage: "create empty bitrise config and secrets",
 		},
+		cli.StringFlag{
+			Name:  "dir",
+			Usage: "directory to scan, defaults to the current directory",
+		},
+		cli.StringSliceFlag{
+			Name:  "scanners",
+			Usage: "run only the given scanners, comma separated, like android,fastlane",
+		},
+		cli.StringSliceFlag{
+			Name:  "skip-scanners",
+			Usage: "do not run the given scanners, comma separated, like cordova",
+		},
+		cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "scan only the paths matching the gitignore style pattern, relative to the scanned directory, like apps/, can be repeated",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "do not scan the paths matching the gitignore style pattern, relative to the scanned directory, like samples/, can be repeated",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "time limit of the scan, like 10m, the scanners still running then are reported as failed, 0s means no limit",
+		},
+		cli.StringSliceFlag{
+			Name:  "scanner-timeout",
+			Usage: "time budget of a scanner as NAME=DURATION, like ios=15m, overriding its default budget, can be repeated",
+		},
+		cli.BoolFlag{
+			Name:  "no-cache",
+			Usage: "do not reuse or store the cached scanner outputs, the outputs are cached by the files the scanners read",
+		},
+		cli.StringFlag{
+			Name:  "config",
+			Value: "./bitrise.yml",
+			Usage: "path of the generated bitrise config",
+		},
+		cli.StringFlag{
+			Name:  "secrets",
+			Value: "./.bitrise.secrets.yml",
+			Usage: "path of the generated bitrise secrets",
+		},
+		cli.StringFlag{
+			Name:  "overwrite",
+			Value: string(overwritePolicyFail),
+			Usage: "what to do if the config or secrets already exist: fail, backup (timestamped copy) or overwrite",
+		},
+		cli.BoolFlag{
+			Name:  "merge",
+			Usage: "merge the detected workflows, app envs and triggers into the existing bitrise config",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "print the changes of the bitrise config, secrets and .gitignore as unified diffs, instead of writing them",
+		},
+		cli.StringFlag{
+			Name:  "answers",
+			Usage: "answer the config questions from the given YAML or JSON file (keyed by option title or env key), instead of asking for them",
+		},
+		cli.StringFlag{
+			Name:  "record",
+			Usage: "save the selected platform, options and config to the given replay file",
+		},
+		cli.StringFlag{
+			Name:  "replay",
+			Usage: "re-run the selections saved in the given replay file against a fresh scan, instead of asking for them",
+		},
+		cli.BoolFlag{
+			Name:  "web",
+			Usage: "answer the config questions in the browser, served by a local web server",
+		},
+		cli.StringFlag{
+			Name:  "web-addr",
+			Value: "localhost:0",
+			Usage: "address of the local web server started with --web, on a free port by default",
+		},
+		cli.BoolFlag{
+			Name:  "no-tui",
+			Usage: "ask the config questions as numbered lists, instead of the full-screen terminal UI",
+		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
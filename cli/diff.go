@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if last := lines[len(lines)-1]; last == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// unifiedDiff returns the changes of the file at the given path as a unified diff,
+// if its content would be replaced by the given content.
+func unifiedDiff(pth, content string) (string, error) {
+	fromFile := pth
+	oldContent, err := ioutil.ReadFile(pth)
+	if os.IsNotExist(err) {
+		fromFile = "/dev/null"
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read %s, error: %s", pth, err)
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(oldContent)),
+		FromFile: fromFile,
+		B:        splitLines(content),
+		ToFile:   pth,
+		Context:  3,
+	})
+}
+
+// outputFile is the content which would be written to the path.
+type outputFile struct {
+	pth     string
+	content string
+}
+
+// printDiffs prints the unified diff of every output which differs from the file on disk.
+func printDiffs(outputs ...outputFile) error {
+	for _, o := range outputs {
+		diff, err := unifiedDiff(o.pth, o.content)
+		if err != nil {
+			return err
+		}
+		if diff == "" {
+			log.Infof("%s is up to date", o.pth)
+			continue
+		}
+		fmt.Print(diff)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_unifiedDiff(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("")
+	require.NoError(t, err)
+
+	t.Log("diffs against /dev/null if the file does not exist")
+	{
+		pth := filepath.Join(tmpDir, ".bitrise.secrets.yml")
+
+		diff, err := unifiedDiff(pth, "envs: []\n")
+		require.NoError(t, err)
+		require.Equal(t, `--- /dev/null
++++ `+pth+`
+@@ -0,0 +1 @@
++envs: []
+`, diff)
+	}
+
+	t.Log("diffs against the existing file")
+	{
+		pth := filepath.Join(tmpDir, ".gitignore")
+		require.NoError(t, ioutil.WriteFile(pth, []byte("node_modules\n"), 0644))
+
+		diff, err := unifiedDiff(pth, "node_modules\n.bitrise.secrets.yml")
+		require.NoError(t, err)
+		require.Equal(t, `--- `+pth+`
++++ `+pth+`
+@@ -1 +1,2 @@
+ node_modules
++.bitrise.secrets.yml
+`, diff)
+
+		diff, err = unifiedDiff(pth, "node_modules\n")
+		require.NoError(t, err)
+		require.Equal(t, "", diff)
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/scanner"
+	"github.com/bitrise-io/bitrise-init/scanners"
+	"github.com/urfave/cli"
+)
+
+func explain(c *cli.Context) error {
+	searchDir := c.String("dir")
+
+	filter, err := scanFilter(c)
+	if err != nil {
+		return err
+	}
+
+	// the detection is not cached, only the scanner budgets apply
+	scannerTimeouts, err := parseScannerTimeouts(c.StringSlice("scanner-timeout"))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel, err := scanContext(c)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	// the explanation is printed to the stdout, the scanner logs would only repeat it
+	previous := scanner.SetLogOutWriter(ioutil.Discard)
+	defer scanner.SetLogOutWriter(previous)
+
+	// the explanation is about the detection, the scanners do not generate their options and configs
+	result := scanner.DetectContext(ctx, searchDir, filter, scanner.Options{Timeouts: scannerTimeouts})
+	if _, err := os.Stdout.WriteString(explanationText(result, filter)); err != nil {
+		return fmt.Errorf("failed to print the explanation, error: %s", err)
+	}
+	return nil
+}
+
+// explanationText returns the decision of every registered scanner: the marker files it looked for and found,
+// the candidates it left out, and the reason it did, or did not detect its platform.
+func explanationText(result models.ScanResultModel, filter scanner.Filter) string {
+	// the text is built in memory and printed at once, the writes of a bytes.Buffer do not fail
+	var w bytes.Buffer
+	for _, errorWithRecommendations := range result.ScannerToErrorsWithRecommendations["general"] {
+		fmt.Fprintf(&w, "error: %s\n\n", errorWithRecommendations.Error)
+	}
+
+	for _, scannerList := range [][]scanners.ScannerInterface{scanners.ProjectScanners, scanners.AutomationToolScanners} {
+		for _, s := range scannerList {
+			name := s.Name()
+			if !filter.Selected(name) {
+				fmt.Fprintf(&w, "%s: skipped, not selected to run\n\n", name)
+				continue
+			}
+
+			fmt.Fprintf(&w, "%s: %s\n", name, explanationStatus(result, name))
+
+			explanation := result.ScannerToExplanation[name]
+			if len(explanation.Markers) > 0 {
+				fmt.Fprintf(&w, "  looked for: %s\n", strings.Join(explanation.Markers, ", "))
+			}
+			printExplanationList(&w, "found", explanation.Found)
+			printExplanationList(&w, "skipped", explanation.Skipped)
+			if detection, ok := result.ScannerToDetection[name]; ok {
+				printExplanationList(&w, "evidence", detection.Evidence)
+			}
+			if explanation.Reason != "" {
+				fmt.Fprintf(&w, "  reason: %s\n", explanation.Reason)
+			}
+
+			var warnings, errs []string
+			warnings = append(warnings, result.ScannerToWarnings[name]...)
+			for _, warning := range result.ScannerToWarningsWithRecommendations[name] {
+				warnings = append(warnings, warning.Error)
+			}
+			errs = append(errs, result.ScannerToErrors[name]...)
+			for _, err := range result.ScannerToErrorsWithRecommendations[name] {
+				errs = append(errs, err.Error)
+			}
+			printExplanationList(&w, "warnings", warnings)
+			printExplanationList(&w, "errors", errs)
+
+			fmt.Fprintln(&w)
+		}
+	}
+
+	if result.Diagnostics != nil {
+		fmt.Fprintf(&w, "files in the search dir:\n%s\n", result.Diagnostics.DirTree)
+	}
+	return w.String()
+}
+
+// explanationStatus returns the outcome of the scanner, like: superseded by flutter (confidence: 70)
+func explanationStatus(result models.ScanResultModel, name string) string {
+	detection, detected := result.ScannerToDetection[name]
+	switch {
+	case detected && detection.SupersededBy != "":
+		return fmt.Sprintf("superseded by %s (confidence: %d)", detection.SupersededBy, detection.Confidence)
+	case len(result.ScannerToErrors[name]) > 0 || len(result.ScannerToErrorsWithRecommendations[name]) > 0:
+		return "failed"
+	case detected:
+		return fmt.Sprintf("detected (confidence: %d)", detection.Confidence)
+	default:
+		return "not detected"
+	}
+}
+
+func printExplanationList(w *bytes.Buffer, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  %s:\n", title)
+	for _, item := range items {
+		// the multiline messages, like the ones with recommendations, are indented as a block
+		fmt.Fprintf(w, "  - %s\n", strings.Replace(item, "\n", "\n    ", -1))
+	}
+}
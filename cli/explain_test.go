@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-init/scanner"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_explanationText(t *testing.T) {
+	searchDir := createProject(t, map[string]string{
+		".gitignore":               "*.sln\n",
+		"App.sln":                  "",
+		"www/config.xml":           "<widget></widget>",
+		"android/build.gradle":     "",
+		"android/settings.gradle":  "",
+		"android/app/build.gradle": "",
+	})
+
+	t.Log("explains why the scanners did, or did not detect their platform")
+	{
+		filter := scanner.Filter{SkipScanners: []string{"fastlane"}}
+		result := scanner.DetectContext(context.Background(), searchDir, filter, scanner.Options{})
+
+		explanation := explanationText(result, filter)
+
+		require.Contains(t, explanation, `cordova: not detected
+  looked for: config.xml
+  found:
+  - www/config.xml
+  reason: www/config.xml found but xmlns:cdv lacks cordova.apache.org
+`)
+		require.Contains(t, explanation, `android: detected (confidence: 70)
+  looked for: build.gradle, build.gradle.kts, settings.gradle, settings.gradle.kts
+  found:
+  - android
+  evidence:
+  - android: Gradle project with build and settings scripts
+  reason: 1 Gradle projects found
+`)
+		require.Contains(t, explanation, `xamarin: not detected
+  looked for: *.sln
+  skipped:
+  - App.sln: .gitignore: *.sln
+  reason: no solution file found
+`)
+		require.Contains(t, explanation, "fastlane: skipped, not selected to run\n")
+	}
+
+	t.Log("the detection does not generate configs, nor use the scan cache")
+	{
+		cacheDir := t.TempDir()
+		result := scanner.DetectContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{Cache: scanner.NewCache(cacheDir, "1.0.0")})
+		require.Contains(t, result.ScannerToDetection, "android")
+		require.Empty(t, result.ScannerToOptionRoot)
+		require.Empty(t, result.ScannerToBitriseConfigMap)
+
+		entries, err := ioutil.ReadDir(cacheDir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	}
+}
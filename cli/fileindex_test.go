@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-init/utility"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func createFileIndexFixture(t *testing.T) string {
+	dir := t.TempDir()
+	for _, pth := range []string{
+		"build.gradle",
+		"app/build.gradle",
+		"app/src/main/AndroidManifest.xml",
+		"app/src/main/java/Main.java",
+		"app/src/test/java/MainTest.java",
+		"ios/Podfile",
+		"ios/App.xcodeproj/project.pbxproj",
+		"lib/main.dart",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(pth)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, pth), []byte(pth), 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "empty"), 0755))
+	return dir
+}
+
+type walkedPath struct {
+	path  string
+	isDir bool
+}
+
+func walkPaths(t *testing.T, walk func(string, filepath.WalkFunc) error, dir string, skip func(string, os.FileInfo) bool) []walkedPath {
+	var paths []walkedPath
+	require.NoError(t, walk(dir, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		paths = append(paths, walkedPath{path: pth, isDir: info.IsDir()})
+		if skip(pth, info) {
+			return filepath.SkipDir
+		}
+		return nil
+	}))
+	return paths
+}
+
+func Test_fileIndexWalk(t *testing.T) {
+	dir := createFileIndexFixture(t)
+	index, err := utility.NewFileIndex(dir, utility.PathFilter{})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name string
+		dir  string
+		skip func(pth string, info os.FileInfo) bool
+	}{
+		{
+			name: "the whole search dir",
+			dir:  dir,
+			skip: func(string, os.FileInfo) bool { return false },
+		},
+		{
+			name: "a sub directory",
+			dir:  filepath.Join(dir, "app"),
+			skip: func(string, os.FileInfo) bool { return false },
+		},
+		{
+			name: "SkipDir on a directory",
+			dir:  dir,
+			skip: func(pth string, info os.FileInfo) bool { return info.IsDir() && info.Name() == "src" },
+		},
+		{
+			name: "SkipDir on a file skips the rest of its directory",
+			dir:  dir,
+			skip: func(pth string, info os.FileInfo) bool { return info.Name() == "AndroidManifest.xml" },
+		},
+		{
+			name: "SkipDir on a file in the walked directory ends the walk",
+			dir:  dir,
+			skip: func(pth string, info os.FileInfo) bool { return info.Name() == "build.gradle" },
+		},
+		{
+			name: "SkipDir on the walked directory",
+			dir:  filepath.Join(dir, "ios"),
+			skip: func(pth string, info os.FileInfo) bool { return info.IsDir() },
+		},
+	} {
+		want := walkPaths(t, filepath.Walk, tt.dir, tt.skip)
+		got := walkPaths(t, index.Walk, tt.dir, tt.skip)
+		require.Equal(t, want, got, tt.name)
+	}
+}
+
+func Test_fileIndexListPathInDirSortedByComponents(t *testing.T) {
+	dir := createFileIndexFixture(t)
+	index, err := utility.NewFileIndex(dir, utility.PathFilter{})
+	require.NoError(t, err)
+
+	for _, pth := range []string{
+		dir,
+		filepath.Join(dir, "app"),
+		filepath.Join(dir, "app", "src"),
+		filepath.Join(dir, "empty"),
+	} {
+		want, err := pathutil.ListPathInDirSortedByComponents(pth, true)
+		require.NoError(t, err)
+
+		got, err := index.ListPathInDirSortedByComponents(pth)
+		require.NoError(t, err)
+		require.Equal(t, want, got, pth)
+	}
+}
+
+func Test_fileIndexIsPathExists(t *testing.T) {
+	dir := createFileIndexFixture(t)
+	index, err := utility.NewFileIndex(dir, utility.PathFilter{})
+	require.NoError(t, err)
+
+	for _, pth := range []string{
+		dir,
+		filepath.Join(dir, "build.gradle"),
+		filepath.Join(dir, "app", "src", "main", "AndroidManifest.xml"),
+		filepath.Join(dir, "empty"),
+		filepath.Join(dir, "settings.gradle"),
+		filepath.Join(dir, "app", "src", "missing", "Main.java"),
+		filepath.Join(filepath.Dir(dir), "missing"),
+	} {
+		want, err := pathutil.IsPathExists(pth)
+		require.NoError(t, err)
+
+		got, err := index.IsPathExists(pth)
+		require.NoError(t, err)
+		require.Equal(t, want, got, pth)
+	}
+}
+
+func Test_fileIndexIgnoreFiles(t *testing.T) {
+	t.Log("the paths ignored by the .gitignore and .bitriseignore files are not indexed")
+	{
+		dir := createProject(t, map[string]string{
+			".gitignore":                    "# build outputs\nbuild/\n",
+			".bitriseignore":                "samples/\n",
+			"libs/.gitignore":               "/legacy\n",
+			"build.gradle":                  "",
+			"app/build/intermediates/a.txt": "",
+			"samples/demo/build.gradle":     "",
+			"libs/legacy/build.gradle":      "",
+			"libs/legacy.gradle":            "",
+		})
+
+		index, err := utility.NewFileIndex(dir, utility.PathFilter{})
+		require.NoError(t, err)
+
+		paths, err := index.ListPathInDirSortedByComponents(dir)
+		require.NoError(t, err)
+		require.Equal(t, []string{".", ".bitriseignore", ".gitignore", "app", "build.gradle", "libs", "libs/.gitignore", "libs/legacy.gradle"}, paths)
+		require.Equal(t, map[string]string{
+			"app/build":   ".gitignore: build/",
+			"samples":     ".bitriseignore: samples/",
+			"libs/legacy": "libs/.gitignore: /legacy",
+		}, index.Excluded())
+	}
+
+	t.Log("the .bitriseignore file can re-include a path ignored by git")
+	{
+		dir := createProject(t, map[string]string{
+			".gitignore":                 "generated/*\n",
+			".bitriseignore":             "!generated/app\n",
+			"generated/app/build.gradle": "",
+			"generated/lib/build.gradle": "",
+		})
+
+		index, err := utility.NewFileIndex(dir, utility.PathFilter{})
+		require.NoError(t, err)
+
+		paths, err := index.ListPathInDirSortedByComponents(dir)
+		require.NoError(t, err)
+		require.Equal(t, []string{".", ".bitriseignore", ".gitignore", "generated", "generated/app", "generated/app/build.gradle"}, paths)
+		require.Equal(t, map[string]string{"generated/lib": ".gitignore: generated/*"}, index.Excluded())
+	}
+
+	t.Log("the .bitriseignore file can re-include a path inside a directory ignored by git")
+	{
+		dir := createProject(t, map[string]string{
+			".gitignore":                     "build/\n*.log\n",
+			".bitriseignore":                 "!build/generated/\n",
+			"build/outputs/app.apk":          "",
+			"build/generated/Main.java":      "",
+			"build/generated/compile.log":    "",
+			"build/generated/ios/Info.plist": "",
+		})
+
+		index, err := utility.NewFileIndex(dir, utility.PathFilter{})
+		require.NoError(t, err)
+
+		paths, err := index.ListPathInDirSortedByComponents(dir)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			".",
+			".bitriseignore",
+			".gitignore",
+			"build/generated",
+			"build/generated/Main.java",
+			"build/generated/ios",
+			"build/generated/ios/Info.plist",
+		}, paths)
+		require.Equal(t, map[string]string{
+			"build/outputs":               ".gitignore: build/",
+			"build/generated/compile.log": ".gitignore: *.log",
+		}, index.Excluded())
+
+		// the excluded paths are left to the file system
+		exists, err := index.IsPathExists(filepath.Join(dir, "build", "outputs", "app.apk"))
+		require.NoError(t, err)
+		require.True(t, exists)
+	}
+}
+
+func Test_fileIndexUnreadableDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("the file permissions are not enforced for root")
+	}
+
+	dir := createProject(t, map[string]string{
+		".gitignore":       "build/\n",
+		"build/app.apk":    "",
+		"app/build.gradle": "",
+		"secret/keys.txt":  "",
+	})
+	secretDir := filepath.Join(dir, "secret")
+	require.NoError(t, os.Chmod(secretDir, 0))
+	t.Cleanup(func() { require.NoError(t, os.Chmod(secretDir, 0755)) })
+
+	index, err := utility.NewFileIndex(dir, utility.PathFilter{})
+	require.NoError(t, err)
+
+	paths, err := index.ListPathInDirSortedByComponents(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{".", ".gitignore", "app", "app/build.gradle"}, paths)
+
+	excluded := index.Excluded()
+	require.Equal(t, ".gitignore: build/", excluded["build"])
+	require.Equal(t, "unreadable: permission denied", excluded["secret"])
+}
@@ -0,0 +1,440 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+)
+
+// configDocument is an existing bitrise config, the merged config is written by extending its content,
+// to keep its comments, its key order and the fields unknown to the config model.
+type configDocument struct {
+	config  bitriseModels.BitriseDataModel
+	content []byte
+}
+
+func readConfig(pth string) (*configDocument, error) {
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bitrise config (%s), error: %s", pth, err)
+	}
+
+	var config bitriseModels.BitriseDataModel
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse bitrise config (%s), error: %s", pth, err)
+	}
+	return &configDocument{config: config, content: content}, nil
+}
+
+// marshal returns the content of the merged config: the workflows, app envs and trigger map items
+// the merged config adds to the existing config are added to the existing content, which is kept otherwise.
+// Without an existing config the merged config is marshalled as is.
+func (doc *configDocument) marshal(merged bitriseModels.BitriseDataModel) ([]byte, error) {
+	if doc == nil {
+		return yaml.Marshal(merged)
+	}
+
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(doc.content, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse bitrise config, error: %s", err)
+	}
+	if document.Kind == 0 {
+		document = yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{{Kind: yamlv3.MappingNode}}}
+	}
+	root := document.Content[0]
+	if root.Kind != yamlv3.MappingNode {
+		return nil, fmt.Errorf("failed to merge bitrise config, error: the config is not a map")
+	}
+
+	existing := doc.config
+	if existing.DefaultStepLibSource == "" && merged.DefaultStepLibSource != "" {
+		if err := setMappingValue(root, "default_step_lib_source", merged.DefaultStepLibSource); err != nil {
+			return nil, err
+		}
+	}
+
+	//
+	// App envs, the existing envs are replaced only if their env options were taken over
+	app, err := mappingValue(root, "app", yamlv3.MappingNode)
+	if err != nil {
+		return nil, err
+	}
+	envs, err := mappingValue(app, "envs", yamlv3.SequenceNode)
+	if err != nil {
+		return nil, err
+	}
+	for i, env := range merged.App.Environments {
+		if i < len(existing.App.Environments) && reflect.DeepEqual(env, existing.App.Environments[i]) {
+			continue
+		}
+		node, err := valueNode(env)
+		if err != nil {
+			return nil, err
+		}
+		if i < len(existing.App.Environments) && i < len(envs.Content) {
+			envs.Content[i] = node
+		} else {
+			envs.Content = append(envs.Content, node)
+		}
+	}
+	removeEmptyValue(app, "envs")
+	removeEmptyValue(root, "app")
+	// ---
+
+	//
+	// Trigger map
+	if len(merged.TriggerMap) > len(existing.TriggerMap) {
+		triggerMap, err := mappingValue(root, "trigger_map", yamlv3.SequenceNode)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range merged.TriggerMap[len(existing.TriggerMap):] {
+			node, err := valueNode(item)
+			if err != nil {
+				return nil, err
+			}
+			triggerMap.Content = append(triggerMap.Content, node)
+		}
+	}
+	// ---
+
+	//
+	// Workflows
+	var addedIDs []string
+	for _, id := range sortedWorkflowIDs(merged.Workflows) {
+		if _, ok := existing.Workflows[id]; !ok {
+			addedIDs = append(addedIDs, id)
+		}
+	}
+	if len(addedIDs) > 0 {
+		workflows, err := mappingValue(root, "workflows", yamlv3.MappingNode)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range addedIDs {
+			node, err := valueNode(merged.Workflows[id])
+			if err != nil {
+				return nil, err
+			}
+			workflows.Content = append(workflows.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: id}, node)
+		}
+	}
+	// ---
+
+	content, err := encodeDocument(&document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bitrise config, error: %s", err)
+	}
+	return content, nil
+}
+
+// mergeSecrets returns the content of the existing secrets, extended by the secrets it has no env for,
+// and the keys of the added secrets. The existing content is kept, like the content of an existing config.
+func mergeSecrets(content []byte, secrets envmanModels.EnvsSerializeModel) ([]byte, []string, error) {
+	var existing envmanModels.EnvsSerializeModel
+	if err := yaml.Unmarshal(content, &existing); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse bitrise secrets, error: %s", err)
+	}
+	existingKeys := map[string]bool{}
+	for _, env := range existing.Envs {
+		key, _, err := env.GetKeyValuePair()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse bitrise secrets, error: %s", err)
+		}
+		existingKeys[key] = true
+	}
+
+	var added []envmanModels.EnvironmentItemModel
+	var addedKeys []string
+	for _, env := range secrets.Envs {
+		key, _, err := env.GetKeyValuePair()
+		if err != nil {
+			return nil, nil, err
+		}
+		if !existingKeys[key] {
+			added = append(added, env)
+			addedKeys = append(addedKeys, key)
+		}
+	}
+	if len(added) == 0 {
+		return content, nil, nil
+	}
+
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &document); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse bitrise secrets, error: %s", err)
+	}
+	if document.Kind == 0 {
+		document = yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{{Kind: yamlv3.MappingNode}}}
+	}
+	root := document.Content[0]
+	if root.Kind != yamlv3.MappingNode {
+		return nil, nil, fmt.Errorf("failed to merge bitrise secrets, error: the secrets are not a map")
+	}
+
+	envs, err := mappingValue(root, "envs", yamlv3.SequenceNode)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, env := range added {
+		node, err := valueNode(env)
+		if err != nil {
+			return nil, nil, err
+		}
+		envs.Content = append(envs.Content, node)
+	}
+
+	merged, err := encodeDocument(&document)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal bitrise secrets, error: %s", err)
+	}
+	return merged, addedKeys, nil
+}
+
+// encodeDocument returns the content of the yaml document, indented like the marshalled configs.
+func encodeDocument(document *yamlv3.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mappingValue returns the value of the key in the mapping, the value is added if the mapping has none.
+func mappingValue(mapping *yamlv3.Node, key string, kind yamlv3.Kind) (*yamlv3.Node, error) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		value := mapping.Content[i+1]
+		if value.Kind == yamlv3.ScalarNode && value.Tag == "!!null" {
+			*value = yamlv3.Node{Kind: kind}
+		}
+		if value.Kind != kind {
+			return nil, fmt.Errorf("failed to merge bitrise config, error: unexpected value of %s", key)
+		}
+		return value, nil
+	}
+
+	value := &yamlv3.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: key}, value)
+	return value, nil
+}
+
+func setMappingValue(mapping *yamlv3.Node, key, value string) error {
+	node, err := valueNode(value)
+	if err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = node
+			return nil
+		}
+	}
+	mapping.Content = append(mapping.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: key}, node)
+	return nil
+}
+
+// removeEmptyValue removes the key of the mapping, if its value was added empty by mappingValue.
+func removeEmptyValue(mapping *yamlv3.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		value := mapping.Content[i+1]
+		if mapping.Content[i].Value == key && value.Kind != yamlv3.ScalarNode && len(value.Content) == 0 && value.Line == 0 {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// valueNode returns the node of the value, marshalled like the configs without an existing config.
+func valueNode(value interface{}) (*yamlv3.Node, error) {
+	content, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bitrise config, error: %s", err)
+	}
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("failed to marshal bitrise config, error: %s", err)
+	}
+	return document.Content[0], nil
+}
+
+// mergeConfigs merges the workflows, app envs and trigger map items of the generated config into the existing config.
+// Nothing existing is overwritten, every conflict is returned as a human readable message:
+// - a workflow with an already existing ID is added with the generated config's project type as prefix
+// - a trigger map item for an already handled event is skipped
+// An app env with an already existing key and a different value fails the merge, as the generated workflows depend on its value.
+func mergeConfigs(existing, generated bitriseModels.BitriseDataModel) (bitriseModels.BitriseDataModel, []string, error) {
+	merged := existing
+	var conflicts []string
+	var envConflicts []string
+
+	if merged.DefaultStepLibSource == "" {
+		merged.DefaultStepLibSource = generated.DefaultStepLibSource
+	}
+
+	//
+	// Workflows
+	merged.Workflows = map[string]bitriseModels.WorkflowModel{}
+	for id, workflow := range existing.Workflows {
+		merged.Workflows[id] = workflow
+	}
+
+	workflowIDMap := map[string]string{}
+	var addedIDs []string
+	for _, id := range sortedWorkflowIDs(generated.Workflows) {
+		workflow := generated.Workflows[id]
+
+		existingWorkflow, ok := merged.Workflows[id]
+		if !ok {
+			merged.Workflows[id] = workflow
+			workflowIDMap[id] = id
+			addedIDs = append(addedIDs, id)
+			continue
+		}
+		if reflect.DeepEqual(existingWorkflow, workflow) {
+			workflowIDMap[id] = id
+			continue
+		}
+
+		newID := generated.ProjectType + "-" + id
+		if _, ok := merged.Workflows[newID]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("workflow (%s) already exists, also as %s, skipping", id, newID))
+			continue
+		}
+		merged.Workflows[newID] = workflow
+		workflowIDMap[id] = newID
+		addedIDs = append(addedIDs, newID)
+		conflicts = append(conflicts, fmt.Sprintf("workflow (%s) already exists, added as %s", id, newID))
+	}
+
+	// rename the referenced workflows of the added workflows
+	for _, id := range addedIDs {
+		workflow := merged.Workflows[id]
+		workflow.BeforeRun = renameWorkflowIDs(workflow.BeforeRun, workflowIDMap)
+		workflow.AfterRun = renameWorkflowIDs(workflow.AfterRun, workflowIDMap)
+		merged.Workflows[id] = workflow
+	}
+	// ---
+
+	//
+	// App envs
+	merged.App.Environments = append([]envmanModels.EnvironmentItemModel{}, existing.App.Environments...)
+	for _, env := range generated.App.Environments {
+		key, value, err := env.GetKeyValuePair()
+		if err != nil {
+			return bitriseModels.BitriseDataModel{}, nil, err
+		}
+
+		idx := -1
+		for i, existingEnv := range merged.App.Environments {
+			existingKey, _, err := existingEnv.GetKeyValuePair()
+			if err != nil {
+				return bitriseModels.BitriseDataModel{}, nil, err
+			}
+			if existingKey == key {
+				idx = i
+				break
+			}
+		}
+
+		if idx == -1 {
+			merged.App.Environments = append(merged.App.Environments, env)
+			continue
+		}
+
+		_, existingValue, err := merged.App.Environments[idx].GetKeyValuePair()
+		if err != nil {
+			return bitriseModels.BitriseDataModel{}, nil, err
+		}
+		if existingValue != value {
+			envConflicts = append(envConflicts, fmt.Sprintf("app env (%s) already exists with a different value (%s) than the generated one (%s)", key, existingValue, value))
+			continue
+		}
+
+		// same key and value, only the env options are taken over
+		if _, ok := env[envmanModels.OptionsKey]; !ok {
+			continue
+		}
+		mergedEnv := envmanModels.EnvironmentItemModel{}
+		for k, v := range merged.App.Environments[idx] {
+			mergedEnv[k] = v
+		}
+		if err := bitriseModels.MergeEnvironmentWith(&mergedEnv, env); err != nil {
+			return bitriseModels.BitriseDataModel{}, nil, err
+		}
+		merged.App.Environments[idx] = mergedEnv
+	}
+	if len(envConflicts) > 0 {
+		return bitriseModels.BitriseDataModel{}, nil, fmt.Errorf("%s, rename or remove the existing app env", strings.Join(envConflicts, ", "))
+	}
+	// ---
+
+	//
+	// Trigger map
+	merged.TriggerMap = append(bitriseModels.TriggerMapModel{}, existing.TriggerMap...)
+	for _, item := range generated.TriggerMap {
+		newID, ok := workflowIDMap[item.WorkflowID]
+		if !ok {
+			continue
+		}
+		item.WorkflowID = newID
+
+		handled := false
+		for _, existingItem := range merged.TriggerMap {
+			if sameTriggerEvent(existingItem, item) {
+				if existingItem.WorkflowID != item.WorkflowID {
+					conflicts = append(conflicts, fmt.Sprintf("trigger map item (%s) already exists, skipping", item.String(true)))
+				}
+				handled = true
+				break
+			}
+		}
+		if !handled {
+			merged.TriggerMap = append(merged.TriggerMap, item)
+		}
+	}
+	// ---
+
+	return merged, conflicts, nil
+}
+
+func sortedWorkflowIDs(workflows map[string]bitriseModels.WorkflowModel) []string {
+	var ids []string
+	for id := range workflows {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func renameWorkflowIDs(ids []string, workflowIDMap map[string]string) []string {
+	var renamed []string
+	for _, id := range ids {
+		if newID, ok := workflowIDMap[id]; ok {
+			id = newID
+		}
+		renamed = append(renamed, id)
+	}
+	return renamed
+}
+
+func sameTriggerEvent(item, otherItem bitriseModels.TriggerMapItemModel) bool {
+	item.WorkflowID, otherItem.WorkflowID = "", ""
+	return item == otherItem
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mergeConfigs(t *testing.T) {
+	existing := bitriseModels.BitriseDataModel{
+		ProjectType: "ios",
+		App: bitriseModels.AppModel{
+			Environments: []envmanModels.EnvironmentItemModel{
+				{"BITRISE_PROJECT_PATH": "ios/App.xcworkspace"},
+				{"PROJECT_LOCATION": "android"},
+			},
+		},
+		TriggerMap: bitriseModels.TriggerMapModel{
+			{PushBranch: "*", WorkflowID: "primary"},
+		},
+		Workflows: map[string]bitriseModels.WorkflowModel{
+			"primary": {Description: "ios primary"},
+		},
+	}
+	generated := bitriseModels.BitriseDataModel{
+		ProjectType: "android",
+		App: bitriseModels.AppModel{
+			Environments: []envmanModels.EnvironmentItemModel{
+				{"PROJECT_LOCATION": "android"},
+				{"MODULE": "app"},
+			},
+		},
+		TriggerMap: bitriseModels.TriggerMapModel{
+			{PushBranch: "*", WorkflowID: "primary"},
+			{PullRequestSourceBranch: "*", WorkflowID: "primary"},
+		},
+		Workflows: map[string]bitriseModels.WorkflowModel{
+			"primary": {Description: "android primary"},
+			"deploy":  {Description: "android deploy"},
+		},
+	}
+
+	merged, conflicts, err := mergeConfigs(existing, generated)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"workflow (primary) already exists, added as android-primary",
+		"trigger map item (push_branch: * -> workflow: android-primary) already exists, skipping",
+	}, conflicts)
+
+	require.Equal(t, "ios", merged.ProjectType)
+	require.Equal(t, map[string]bitriseModels.WorkflowModel{
+		"primary":         {Description: "ios primary"},
+		"android-primary": {Description: "android primary"},
+		"deploy":          {Description: "android deploy"},
+	}, merged.Workflows)
+	require.Equal(t, []envmanModels.EnvironmentItemModel{
+		{"BITRISE_PROJECT_PATH": "ios/App.xcworkspace"},
+		{"PROJECT_LOCATION": "android"},
+		{"MODULE": "app"},
+	}, merged.App.Environments)
+	require.Equal(t, bitriseModels.TriggerMapModel{
+		{PushBranch: "*", WorkflowID: "primary"},
+		{PullRequestSourceBranch: "*", WorkflowID: "android-primary"},
+	}, merged.TriggerMap)
+
+	require.Equal(t, "ios primary", existing.Workflows["primary"].Description)
+	require.Equal(t, 1, len(existing.TriggerMap))
+
+	t.Log("fails on an app env with a different value")
+	{
+		existing.App.Environments = []envmanModels.EnvironmentItemModel{{"PROJECT_LOCATION": "mobile"}}
+
+		_, _, err := mergeConfigs(existing, generated)
+		require.EqualError(t, err, "app env (PROJECT_LOCATION) already exists with a different value (mobile) than the generated one (android), rename or remove the existing app env")
+	}
+}
+
+func Test_configDocument_marshal(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "bitrise.yml")
+	require.NoError(t, ioutil.WriteFile(pth, []byte(`# the config of the iOS app
+format_version: "8"
+project_type: ios
+meta:
+  bitrise.io:
+    stack: osx-xcode-13.2.x
+app:
+  envs:
+  - BITRISE_PROJECT_PATH: ios/App.xcworkspace # the workspace
+workflows:
+  primary:
+    description: ios primary
+`), 0644))
+
+	existing, err := readConfig(pth)
+	require.NoError(t, err)
+
+	generated := bitriseModels.BitriseDataModel{
+		App: bitriseModels.AppModel{
+			Environments: []envmanModels.EnvironmentItemModel{{"MODULE": "app"}},
+		},
+		TriggerMap: bitriseModels.TriggerMapModel{{PushBranch: "*", WorkflowID: "deploy"}},
+		Workflows:  map[string]bitriseModels.WorkflowModel{"deploy": {Description: "android deploy"}},
+	}
+	merged, _, err := generateConfig(generated, existing)
+	require.NoError(t, err)
+
+	content, err := existing.marshal(merged)
+	require.NoError(t, err)
+	require.Equal(t, `# the config of the iOS app
+format_version: "8"
+project_type: ios
+meta:
+  bitrise.io:
+    stack: osx-xcode-13.2.x
+app:
+  envs:
+    - BITRISE_PROJECT_PATH: ios/App.xcworkspace # the workspace
+    - MODULE: app
+workflows:
+  primary:
+    description: ios primary
+  deploy:
+    description: android deploy
+trigger_map:
+  - push_branch: '*'
+    workflow: deploy
+`, string(content))
+}
+
+func Test_mergeSecrets(t *testing.T) {
+	secrets := envmanModels.EnvsSerializeModel{Envs: []envmanModels.EnvironmentItemModel{
+		{"KEYSTORE_PASSWORD": ""},
+		{"KEY_ALIAS": ""},
+	}}
+
+	t.Log("the missing secrets are added to the existing secrets, which are kept")
+	{
+		content, added, err := mergeSecrets([]byte(`# the secrets of the iOS app
+envs:
+- KEYSTORE_PASSWORD: secret # set by hand
+- APPLE_ID: dev@example.com
+`), secrets)
+		require.NoError(t, err)
+		require.Equal(t, []string{"KEY_ALIAS"}, added)
+		require.Equal(t, `# the secrets of the iOS app
+envs:
+  - KEYSTORE_PASSWORD: secret # set by hand
+  - APPLE_ID: dev@example.com
+  - KEY_ALIAS: ""
+`, string(content))
+	}
+
+	t.Log("the existing secrets are unchanged, if they have every secret")
+	{
+		existing := []byte("envs:\n- KEYSTORE_PASSWORD: secret\n- KEY_ALIAS: alias\n")
+		content, added, err := mergeSecrets(existing, secrets)
+		require.NoError(t, err)
+		require.Empty(t, added)
+		require.Equal(t, string(existing), string(content))
+	}
+
+	t.Log("an empty secrets file gets the secrets")
+	{
+		content, added, err := mergeSecrets(nil, secrets)
+		require.NoError(t, err)
+		require.Equal(t, []string{"KEYSTORE_PASSWORD", "KEY_ALIAS"}, added)
+		require.Equal(t, "envs:\n  - KEYSTORE_PASSWORD: \"\"\n  - KEY_ALIAS: \"\"\n", string(content))
+	}
+}
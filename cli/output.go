@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/bitrise-io/go-utils/pathutil"
+)
+
+// overwritePolicy defines what happens if an output file already exists.
+type overwritePolicy string
+
+const (
+	overwritePolicyFail      overwritePolicy = "fail"
+	overwritePolicyBackup    overwritePolicy = "backup"
+	overwritePolicyOverwrite overwritePolicy = "overwrite"
+)
+
+// the backups of the runs within the same second are told apart by the microseconds
+const backupTimestampLayout = "20060102150405.000000"
+
+// maxBackupSuffix limits the numbered suffixes tried, if the backups of the same timestamp exist
+const maxBackupSuffix = 100
+
+func parseOverwritePolicy(policy string) (overwritePolicy, error) {
+	switch p := overwritePolicy(policy); p {
+	case overwritePolicyFail, overwritePolicyBackup, overwritePolicyOverwrite:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid overwrite policy (%s), available: %s, %s, %s", policy, overwritePolicyFail, overwritePolicyBackup, overwritePolicyOverwrite)
+}
+
+// backupOutputs creates a timestamped copy of every existing output path, if the policy requires it.
+func backupOutputs(policy overwritePolicy, now time.Time, pths ...string) error {
+	if policy != overwritePolicyBackup {
+		return nil
+	}
+
+	for _, pth := range pths {
+		exist, err := pathutil.IsPathExists(pth)
+		if err != nil {
+			return err
+		} else if !exist {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(pth)
+		if err != nil {
+			return fmt.Errorf("failed to read %s, error: %s", pth, err)
+		}
+
+		if err := writeBackup(pth, now, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBackup writes the content to a new timestamped backup of the path, an existing backup is never overwritten:
+// a numbered suffix is added to the name, if the backup of the same timestamp exists.
+func writeBackup(pth string, now time.Time, content []byte) error {
+	timestamp := now.Format(backupTimestampLayout)
+	for i := 0; i < maxBackupSuffix; i++ {
+		backupPth := fmt.Sprintf("%s.%s.bak", pth, timestamp)
+		if i > 0 {
+			backupPth = fmt.Sprintf("%s.%s-%d.bak", pth, timestamp, i)
+		}
+
+		file, err := os.OpenFile(backupPth, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to backup %s, error: %s", pth, err)
+		}
+
+		if _, err := file.Write(content); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to backup %s, error: %s", pth, err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to backup %s, error: %s", pth, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to backup %s, error: the backups of %s exist", pth, timestamp)
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_backupOutputs(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	t.Log("creates timestamped copies of the existing outputs")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("")
+		require.NoError(t, err)
+
+		configPth := filepath.Join(tmpDir, "bitrise.yml")
+		require.NoError(t, ioutil.WriteFile(configPth, []byte("format_version: 4"), 0644))
+
+		err = backupOutputs(overwritePolicyBackup, now, configPth, filepath.Join(tmpDir, ".bitrise.secrets.yml"))
+		require.NoError(t, err)
+
+		contents, err := ioutil.ReadFile(configPth + ".20200102030405.000006.bak")
+		require.NoError(t, err)
+		require.Equal(t, "format_version: 4", string(contents))
+
+		exist, err := pathutil.IsPathExists(filepath.Join(tmpDir, ".bitrise.secrets.yml.20200102030405.000006.bak"))
+		require.NoError(t, err)
+		require.False(t, exist)
+	}
+
+	t.Log("does not overwrite the backup of the same timestamp")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("")
+		require.NoError(t, err)
+
+		configPth := filepath.Join(tmpDir, "bitrise.yml")
+		require.NoError(t, ioutil.WriteFile(configPth, []byte("format_version: 4"), 0644))
+		require.NoError(t, backupOutputs(overwritePolicyBackup, now, configPth))
+
+		require.NoError(t, ioutil.WriteFile(configPth, []byte("format_version: 5"), 0644))
+		require.NoError(t, backupOutputs(overwritePolicyBackup, now, configPth))
+
+		contents, err := ioutil.ReadFile(configPth + ".20200102030405.000006.bak")
+		require.NoError(t, err)
+		require.Equal(t, "format_version: 4", string(contents))
+
+		contents, err = ioutil.ReadFile(configPth + ".20200102030405.000006-1.bak")
+		require.NoError(t, err)
+		require.Equal(t, "format_version: 5", string(contents))
+	}
+
+	t.Log("does not create copies with the overwrite policy")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("")
+		require.NoError(t, err)
+
+		configPth := filepath.Join(tmpDir, "bitrise.yml")
+		require.NoError(t, ioutil.WriteFile(configPth, []byte("format_version: 4"), 0644))
+
+		require.NoError(t, backupOutputs(overwritePolicyOverwrite, now, configPth))
+
+		exist, err := pathutil.IsPathExists(configPth + ".20200102030405.000006.bak")
+		require.NoError(t, err)
+		require.False(t, exist)
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/scanner"
+	"github.com/bitrise-io/go-utils/fileutil"
+)
+
+func readSelection(pth string) (scanner.Selection, error) {
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return scanner.Selection{}, fmt.Errorf("failed to read replay file (%s), error: %s", pth, err)
+	}
+
+	var selection scanner.Selection
+	if err := yaml.Unmarshal(content, &selection); err != nil {
+		return scanner.Selection{}, fmt.Errorf("failed to parse replay file (%s), error: %s", pth, err)
+	}
+	return selection, nil
+}
+
+func writeSelection(pth string, selection scanner.Selection) error {
+	content, err := yaml.Marshal(selection)
+	if err != nil {
+		return fmt.Errorf("failed to marshal replay, error: %s", err)
+	}
+
+	if err := fileutil.WriteBytesToFile(pth, content); err != nil {
+		return fmt.Errorf("failed to write replay file (%s), error: %s", pth, err)
+	}
+	return nil
+}
+
+func answerKey(answer scanner.OptionAnswer) string {
+	if answer.EnvKey != "" {
+		return answer.EnvKey
+	}
+	return answer.Title
+}
+
+// replaySelection re-runs the recorded selection against the scan result,
+// it fails if the recorded path is no longer valid or leads to a different config.
+func replaySelection(scanResult models.ScanResultModel, recorded scanner.Selection) (scanner.Selection, error) {
+	options, ok := scanResult.ScannerToOptionRoot[recorded.Platform]
+	if !ok {
+		var platforms []string
+		for platform := range scanResult.ScannerToOptionRoot {
+			platforms = append(platforms, platform)
+		}
+		sort.Strings(platforms)
+		return scanner.Selection{}, fmt.Errorf("recorded platform (%s) not detected, detected platforms: %s", recorded.Platform, strings.Join(platforms, ", "))
+	}
+
+	a := answers{}
+	for _, answer := range recorded.Answers {
+		a[answerKey(answer)] = answer.Value
+	}
+
+	configName, selected, err := answerOptions(options, a)
+	if err != nil {
+		return scanner.Selection{}, err
+	}
+
+	recordedKeys := map[string]bool{}
+	for _, answer := range recorded.Answers {
+		recordedKeys[answerKey(answer)] = true
+	}
+	selectedKeys := map[string]bool{}
+	for _, answer := range selected {
+		selectedKeys[answerKey(answer)] = true
+	}
+
+	var mismatches []string
+	for _, answer := range recorded.Answers {
+		if !selectedKeys[answerKey(answer)] {
+			mismatches = append(mismatches, fmt.Sprintf("- %s: recorded, but not asked", answerKey(answer)))
+		}
+	}
+	for _, answer := range selected {
+		if !recordedKeys[answerKey(answer)] {
+			mismatches = append(mismatches, fmt.Sprintf("- %s: asked, but not recorded", answerKey(answer)))
+		}
+	}
+	if recorded.ConfigName != configName {
+		mismatches = append(mismatches, fmt.Sprintf("- config: recorded %s, but %s selected", recorded.ConfigName, configName))
+	}
+	if len(mismatches) > 0 {
+		return scanner.Selection{}, fmt.Errorf("recorded answers do not match the scan result:\n%s", strings.Join(mismatches, "\n"))
+	}
+
+	return scanner.Selection{
+		Platform:   recorded.Platform,
+		Answers:    selected,
+		ConfigName: configName,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/scanner"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_replaySelection(t *testing.T) {
+	scanResult := models.ScanResultModel{
+		ScannerToOptionRoot: map[string]models.OptionNode{
+			"ios": testOptionTree(),
+		},
+	}
+	recorded := scanner.Selection{
+		Platform: "ios",
+		Answers: []scanner.OptionAnswer{
+			{Title: "Project path", EnvKey: "PROJECT_PATH", Value: "App.xcodeproj"},
+			{Title: "Scheme", EnvKey: "SCHEME", Value: "AppTests"},
+			{Title: "Export method", EnvKey: "EXPORT_METHOD", Value: "app-store"},
+			{Title: "Variant", EnvKey: "VARIANT", Value: "release"},
+		},
+		ConfigName: "ios-config",
+	}
+
+	t.Log("replays the recorded selection")
+	{
+		selection, err := replaySelection(scanResult, recorded)
+		require.NoError(t, err)
+		require.Equal(t, recorded, selection)
+	}
+
+	t.Log("fails if the recorded platform is not detected")
+	{
+		_, err := replaySelection(models.ScanResultModel{
+			ScannerToOptionRoot: map[string]models.OptionNode{"android": {}},
+		}, recorded)
+		require.EqualError(t, err, "recorded platform (ios) not detected, detected platforms: android")
+	}
+
+	t.Log("fails if a recorded answer is not asked")
+	{
+		schemeOption := models.NewOption("Scheme", "", "SCHEME", models.TypeSelector)
+		schemeOption.AddConfig("AppTests", models.NewConfigOption("ios-config", nil))
+
+		_, err := replaySelection(models.ScanResultModel{
+			ScannerToOptionRoot: map[string]models.OptionNode{"ios": *schemeOption},
+		}, recorded)
+		require.EqualError(t, err, `recorded answers do not match the scan result:
+- PROJECT_PATH: recorded, but not asked
+- EXPORT_METHOD: recorded, but not asked
+- VARIANT: recorded, but not asked`)
+	}
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/output"
+	"github.com/bitrise-io/bitrise-init/scanner"
+	"github.com/bitrise-io/bitrise-init/scanners"
+	"github.com/bitrise-io/bitrise-plugins-init/version"
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/urfave/cli"
+)
+
+func scan(c *cli.Context) error {
+	format, err := output.ParseFormat(c.String("format"))
+	if err != nil {
+		return err
+	}
+
+	searchDir := c.String("dir")
+
+	filter, err := scanFilter(c)
+	if err != nil {
+		return err
+	}
+
+	options, err := scanOptions(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel, err := scanContext(c)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if outputDir := c.String("output-dir"); outputDir != "" {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output dir (%s), error: %s", outputDir, err)
+		}
+
+		if _, err := scanner.GenerateAndWriteResultsContext(ctx, searchDir, outputDir, format, filter, options); err != nil {
+			return err
+		}
+
+		log.Infof("scan result generated at: %s", outputDir)
+		return nil
+	}
+
+	// the scan result is printed to the stdout, the scanner logs are redirected to keep it clean
+	previous := scanner.SetLogOutWriter(os.Stderr)
+	defer scanner.SetLogOutWriter(previous)
+
+	result, detected := scanner.GenerateScanResultContext(ctx, searchDir, filter, options)
+	printed, err := newScanOutput(result)
+	if err != nil {
+		return err
+	}
+	if err := output.Print(printed, format); err != nil {
+		return fmt.Errorf("failed to print scan result, error: %s", err)
+	}
+
+	if !detected {
+		return errors.New("no known platform type detected, run the explain command to see why")
+	}
+	return nil
+}
+
+// scanOutput is the scan result printed to the stdout. There is no icons dir next to it,
+// so the app icons referenced by the options are inlined, base64 encoded, by their file name.
+type scanOutput struct {
+	models.ScanResultModel `yaml:",inline"`
+	Icons                  map[string]string `json:"icons,omitempty" yaml:"icons,omitempty"`
+}
+
+func newScanOutput(result models.ScanResultModel) (scanOutput, error) {
+	printed := scanOutput{ScanResultModel: result}
+	for _, icon := range result.Icons {
+		content, err := ioutil.ReadFile(icon.Path)
+		if err != nil {
+			return scanOutput{}, fmt.Errorf("failed to read icon (%s), error: %s", icon.Path, err)
+		}
+		if printed.Icons == nil {
+			printed.Icons = map[string]string{}
+		}
+		printed.Icons[icon.Filename] = base64.StdEncoding.EncodeToString(content)
+	}
+	return printed, nil
+}
+
+// scanFilter returns the scanners and paths to scan, selected by the --scanners, --skip-scanners, --include and --exclude flags.
+func scanFilter(c *cli.Context) (scanner.Filter, error) {
+	filter := scanner.Filter{
+		Scanners:     splitList(c.StringSlice("scanners")),
+		SkipScanners: splitList(c.StringSlice("skip-scanners")),
+		Include:      c.StringSlice("include"),
+		Exclude:      c.StringSlice("exclude"),
+	}
+	if err := filter.Validate(); err != nil {
+		return scanner.Filter{}, fmt.Errorf("invalid scan filter, error: %s", err)
+	}
+	return filter, nil
+}
+
+// splitList splits the comma separated values.
+func splitList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// scanOptions returns the scanner budgets given by the --scanner-timeout flags, and the scan cache, unless --no-cache is set.
+func scanOptions(c *cli.Context) (scanner.Options, error) {
+	scannerTimeouts, err := parseScannerTimeouts(c.StringSlice("scanner-timeout"))
+	if err != nil {
+		return scanner.Options{}, err
+	}
+
+	options := scanner.Options{Timeouts: scannerTimeouts}
+	if !c.Bool("no-cache") {
+		options.Cache = scanCache()
+	}
+	return options, nil
+}
+
+// scanContext returns the context of the scan, limited by the --timeout flag.
+func scanContext(c *cli.Context) (context.Context, context.CancelFunc, error) {
+	timeout := c.Duration("timeout")
+	if timeout < 0 {
+		return nil, nil, fmt.Errorf("invalid timeout (%s), it can not be negative", timeout)
+	}
+	if timeout == 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	return ctx, cancel, nil
+}
+
+// scanCache returns the scan cache in the user cache dir, or nil if the user cache dir is unknown.
+func scanCache() *scanner.Cache {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		log.Warnf("Scan cache disabled, failed to find the user cache dir, error: %s", err)
+		return nil
+	}
+	return scanner.NewCache(filepath.Join(cacheDir, "bitrise-init", "scan"), version.VERSION)
+}
+
+// parseScannerTimeouts parses the NAME=DURATION scanner budgets.
+func parseScannerTimeouts(values []string) (map[string]time.Duration, error) {
+	var scannerNames []string
+	for _, scannerList := range [][]scanners.ScannerInterface{scanners.ProjectScanners, scanners.AutomationToolScanners} {
+		for _, s := range scannerList {
+			scannerNames = append(scannerNames, s.Name())
+		}
+	}
+
+	timeouts := map[string]time.Duration{}
+	for _, value := range values {
+		split := strings.SplitN(value, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid scanner timeout (%s), expected NAME=DURATION", value)
+		}
+
+		name := strings.TrimSpace(split[0])
+		if !sliceutil.IsStringInSlice(name, scannerNames) {
+			return nil, fmt.Errorf("invalid scanner timeout (%s), unknown scanner: %s, available scanners: %s", value, name, strings.Join(scannerNames, ", "))
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(split[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid scanner timeout (%s), error: %s", value, err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("invalid scanner timeout (%s), it has to be positive", value)
+		}
+
+		timeouts[name] = timeout
+	}
+	return timeouts, nil
+}
@@ -0,0 +1,595 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/scanner"
+	"github.com/bitrise-io/bitrise-init/utility"
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/stretchr/testify/require"
+)
+
+// createProject writes the files of a project into a temporary dir removed after the test,
+// and silences the scanner logs until the test ends.
+func createProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	previous := scanner.SetLogOutWriter(ioutil.Discard)
+	t.Cleanup(func() { scanner.SetLogOutWriter(previous) })
+
+	dir := t.TempDir()
+	for pth, content := range files {
+		pth = filepath.Join(dir, pth)
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0755))
+		require.NoError(t, ioutil.WriteFile(pth, []byte(content), 0644))
+	}
+	return dir
+}
+
+func detectedScanners(result models.ScanResultModel) []string {
+	var names []string
+	for name := range result.ScannerToOptionRoot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func optionValues(result models.ScanResultModel, scannerName string) []string {
+	option := result.ScannerToOptionRoot[scannerName]
+	return option.GetValues()
+}
+
+const xamarinSolution = `Global
+	GlobalSection(SolutionConfigurationPlatforms) = preSolution
+		Release|iPhone = Release|iPhone
+	EndGlobalSection
+EndGlobal
+`
+
+func Test_scanConcurrently(t *testing.T) {
+	androidDir := createProject(t, map[string]string{
+		"android-app/build.gradle":     "",
+		"android-app/settings.gradle":  "",
+		"android-app/gradlew":          "",
+		"android-app/app/build.gradle": "",
+	})
+	xamarinDir := createProject(t, map[string]string{
+		"src/App.sln": xamarinSolution,
+	})
+
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	t.Log("scans of different directories can run at the same time")
+	{
+		const scansPerDir = 4
+		results := make([]models.ScanResultModel, 2*scansPerDir)
+
+		var wg sync.WaitGroup
+		for i := range results {
+			searchDir := androidDir
+			if i%2 == 1 {
+				searchDir = xamarinDir
+			}
+
+			wg.Add(1)
+			go func(i int, searchDir string) {
+				defer wg.Done()
+				results[i] = scanner.Config(searchDir)
+			}(i, searchDir)
+		}
+		wg.Wait()
+
+		for i, result := range results {
+			if i%2 == 0 {
+				require.Equal(t, []string{"android"}, detectedScanners(result), "scan #%d", i)
+				require.Equal(t, []string{"android-app"}, optionValues(result, "android"))
+			} else {
+				require.Equal(t, []string{"xamarin"}, detectedScanners(result), "scan #%d", i)
+				require.Equal(t, []string{"src/App.sln"}, optionValues(result, "xamarin"))
+			}
+		}
+	}
+
+	t.Log("running the scanners concurrently gives the result of running them one after the other")
+	{
+		searchDir := createProject(t, map[string]string{
+			"flutter/pubspec.yaml":             "name: app\n",
+			"flutter/android/build.gradle":     "",
+			"flutter/android/settings.gradle":  "",
+			"flutter/android/gradlew":          "",
+			"flutter/android/app/build.gradle": "",
+			"ionic/config.xml":                 `<widget xmlns:cdv="http://cordova.apache.org/ns/1.0"></widget>`,
+			"ionic/ionic.config.json":          "{}",
+			"ionic/package.json":               "{}",
+			"android-app/build.gradle":         "",
+			"android-app/settings.gradle":      "",
+			"android-app/gradlew":              "",
+			"android-app/app/build.gradle":     "",
+			"src/App.sln":                      xamarinSolution,
+			"fastlane/Fastfile":                "lane :test do\nend\n",
+		})
+
+		sequential := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{Sequential: true})
+		require.Equal(t, []string{"fastlane", "flutter", "ionic", "xamarin"}, detectedScanners(sequential))
+		require.Equal(t, "flutter", sequential.ScannerToDetection["android"].SupersededBy)
+		require.Equal(t, "ionic", sequential.ScannerToDetection["cordova"].SupersededBy)
+
+		for i := 0; i < 4; i++ {
+			concurrent := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+			require.Equal(t, sequential, concurrent, "scan #%d", i)
+		}
+	}
+
+	t.Log("the working directory is not changed")
+	{
+		dir, err := os.Getwd()
+		require.NoError(t, err)
+		require.Equal(t, currentDir, dir)
+	}
+}
+
+func Test_scanTimeout(t *testing.T) {
+	searchDir := createProject(t, map[string]string{
+		"build.gradle":     "",
+		"settings.gradle":  "",
+		"gradlew":          "",
+		"app/build.gradle": "",
+	})
+
+	t.Log("a scanner not finished in its budget is reported with a recommendation")
+	{
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{DefaultTimeout: time.Nanosecond})
+
+		require.Equal(t, 0, len(result.ScannerToOptionRoot))
+
+		errs := result.ScannerToErrorsWithRecommendations["android"]
+		require.Equal(t, 1, len(errs))
+		require.Equal(t, "android scanner did not finish in 1ns", errs[0].Error)
+		require.NotNil(t, errs[0].Recommendations)
+	}
+
+	t.Log("the scanners are stopped, once the scan is cancelled")
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result := scanner.ConfigContext(ctx, searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, 0, len(result.ScannerToOptionRoot))
+
+		errs := result.ScannerToErrorsWithRecommendations["android"]
+		require.Equal(t, 1, len(errs))
+		require.Equal(t, "android scanner stopped, the scan was cancelled", errs[0].Error)
+	}
+
+	t.Log("the scanners finished in their budget are reported as usual")
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		result := scanner.ConfigContext(ctx, searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, []string{"android"}, detectedScanners(result))
+		require.Equal(t, 0, len(result.ScannerToErrorsWithRecommendations["android"]))
+	}
+}
+
+func Test_parseScannerTimeouts(t *testing.T) {
+	t.Log("parses the scanner budgets")
+	{
+		timeouts, err := parseScannerTimeouts([]string{"ios=15m", "android = 30s"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]time.Duration{"ios": 15 * time.Minute, "android": 30 * time.Second}, timeouts)
+	}
+
+	t.Log("fails for invalid budgets")
+	{
+		for _, value := range []string{"ios", "unknown=1m", "ios=soon", "ios=0s", "ios=-1m"} {
+			_, err := parseScannerTimeouts([]string{value})
+			require.Error(t, err, value)
+		}
+	}
+}
+
+func Test_scannerTimeout(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		options     scanner.Options
+		scannerName string
+		want        time.Duration
+	}{
+		{name: "default", options: scanner.Options{}, scannerName: "android", want: scanner.DefaultScannerTimeout},
+		{name: "default of the scanner", options: scanner.Options{}, scannerName: "ios", want: 10 * time.Minute},
+		{name: "given default", options: scanner.Options{DefaultTimeout: time.Second}, scannerName: "android", want: time.Second},
+		{name: "given default does not shorten the default of the scanner", options: scanner.Options{DefaultTimeout: time.Second}, scannerName: "ios", want: 10 * time.Minute},
+		{name: "given timeout", options: scanner.Options{Timeouts: map[string]time.Duration{"ios": time.Minute}, DefaultTimeout: time.Second}, scannerName: "ios", want: time.Minute},
+	} {
+		require.Equal(t, tt.want, tt.options.ScannerTimeout(tt.scannerName), tt.name)
+	}
+}
+
+func Test_scanFilter(t *testing.T) {
+	searchDir := createProject(t, map[string]string{
+		"apps/android/build.gradle":     "",
+		"apps/android/settings.gradle":  "",
+		"apps/android/gradlew":          "",
+		"apps/android/app/build.gradle": "",
+		"apps/android/fastlane/Fastfile": `lane :beta do
+end
+`,
+		"samples/android/build.gradle":     "",
+		"samples/android/settings.gradle":  "",
+		"samples/android/gradlew":          "",
+		"samples/android/app/build.gradle": "",
+		"www/config.xml":                   "<widget></widget>",
+	})
+
+	t.Log("every scanner runs on every path by default")
+	{
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, []string{"android", "fastlane"}, detectedScanners(result))
+		require.Equal(t, []string{"apps/android", "samples/android"}, optionValues(result, "android"))
+	}
+
+	t.Log("the selected scanners run on the included paths")
+	{
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{
+			Scanners: []string{"android"},
+			Include:  []string{"apps/"},
+		}, scanner.Options{})
+		require.Equal(t, []string{"android"}, detectedScanners(result))
+		require.Equal(t, []string{"apps/android"}, optionValues(result, "android"))
+		require.Contains(t, result.ExcludedPaths.ReasonToCount, "not matching the include patterns")
+	}
+
+	t.Log("the skipped scanners do not run, the excluded paths are not scanned")
+	{
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{
+			SkipScanners: []string{"fastlane"},
+			Exclude:      []string{"samples/**"},
+		}, scanner.Options{})
+		require.Equal(t, []string{"android"}, detectedScanners(result))
+		require.Equal(t, []string{"apps/android"}, optionValues(result, "android"))
+		require.Equal(t, 1, result.ExcludedPaths.ReasonToCount["exclude pattern: samples/**"])
+	}
+
+	t.Log("unknown scanners and malformed patterns are reported")
+	{
+		for _, filter := range []scanner.Filter{
+			{Scanners: []string{"unknown"}},
+			{SkipScanners: []string{"unknown"}},
+			{Include: []string{"apps/[a"}},
+		} {
+			require.Error(t, filter.Validate())
+
+			result := scanner.ConfigContext(context.Background(), searchDir, filter, scanner.Options{})
+			require.Equal(t, 0, len(result.ScannerToOptionRoot))
+			require.Equal(t, 1, len(result.ScannerToErrorsWithRecommendations["general"]))
+		}
+	}
+}
+
+func Test_splitList(t *testing.T) {
+	require.Equal(t, []string{"android", "fastlane", "ios"}, splitList([]string{"android, fastlane", "ios", ""}))
+	require.Equal(t, 0, len(splitList(nil)))
+}
+
+func Test_scanDetections(t *testing.T) {
+	t.Log("the native scanners are superseded by a cross-platform project containing their projects")
+	{
+		searchDir := createProject(t, map[string]string{
+			"pubspec.yaml":             "name: app\n",
+			"android/build.gradle":     "",
+			"android/settings.gradle":  "",
+			"android/gradlew":          "",
+			"android/app/build.gradle": "",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, []string{"flutter"}, detectedScanners(result))
+
+		require.Equal(t, models.CrossPlatformWithNativeConfidence, result.ScannerToDetection["flutter"].Confidence)
+		require.Equal(t, []string{"pubspec.yaml: Flutter project with an Android project"}, result.ScannerToDetection["flutter"].Evidence)
+
+		android := result.ScannerToDetection["android"]
+		require.Equal(t, models.NativeConfidence, android.Confidence)
+		require.Equal(t, "flutter", android.SupersededBy)
+		_, ok := result.ScannerToWarnings["android"]
+		require.False(t, ok)
+	}
+
+	t.Log("the cordova scanner detects an ionic project with a low confidence, the ionic scanner supersedes it")
+	{
+		searchDir := createProject(t, map[string]string{
+			"config.xml":        `<widget xmlns:cdv="http://cordova.apache.org/ns/1.0"></widget>`,
+			"ionic.config.json": "{}",
+			"package.json":      "{}",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, []string{"ionic"}, detectedScanners(result))
+
+		cordova := result.ScannerToDetection["cordova"]
+		require.Equal(t, models.LowConfidence, cordova.Confidence)
+		require.Equal(t, "ionic", cordova.SupersededBy)
+		require.Equal(t, []string{"config.xml: Cordova widget", "ionic.config.json: seems to be an Ionic project"}, cordova.Evidence)
+	}
+
+	t.Log("of the overlapping scanners detected with the same confidence, the one excluding the other supersedes it")
+	{
+		searchDir := createProject(t, map[string]string{
+			"ionic.config.json":   "{}",
+			"package.json":        "{}",
+			"legacy/config.xml":   `<widget xmlns:cdv="http://cordova.apache.org/ns/1.0"></widget>`,
+			"legacy/package.json": "{}",
+		})
+
+		for _, sequential := range []bool{false, true} {
+			result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{Sequential: sequential})
+			require.Equal(t, []string{"ionic"}, detectedScanners(result))
+
+			cordova := result.ScannerToDetection["cordova"]
+			require.Equal(t, result.ScannerToDetection["ionic"].Confidence, cordova.Confidence)
+			require.Equal(t, "ionic", cordova.SupersededBy)
+		}
+	}
+}
+
+func Test_scanDiagnostics(t *testing.T) {
+	t.Log("the scan detecting no platform renders the directory tree, with the excluded paths")
+	{
+		searchDir := createProject(t, map[string]string{
+			".gitignore":             "build/\n",
+			"README.md":              "",
+			"build/output.txt":       "",
+			"docs/guide.md":          "",
+			"node_modules/lib/index": "",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, 0, len(detectedScanners(result)))
+		require.NotNil(t, result.Diagnostics)
+		require.Equal(t, `.
+├── .gitignore
+├── README.md
+├── build (excluded, .gitignore: build/)
+├── docs/
+│   └── guide.md
+└── node_modules (excluded, skipped directory: node_modules)
+
+1 directories, 3 files, 2 excluded`, result.Diagnostics.DirTree)
+	}
+
+	t.Log("the directory tree is limited in depth and in entries")
+	{
+		searchDir := createProject(t, map[string]string{
+			"a/b/c/d.txt": "",
+			"e/1.txt":     "",
+			"e/2.txt":     "",
+			"e/3.txt":     "",
+		})
+
+		index, err := utility.NewFileIndex(searchDir, utility.PathFilter{})
+		require.NoError(t, err)
+		require.Equal(t, `.
+├── a/
+│   └── b/
+└── e/
+    ├── 1.txt
+    ├── 2.txt
+    └── ... 1 more entries
+
+3 directories, 2 files, 0 excluded`, index.RenderTree(utility.TreeOptions{MaxDepth: 2, MaxDirEntries: 2}))
+
+		require.Equal(t, `.
+├── a/
+│   └── b/
+... output truncated after 2 entries
+
+2 directories, 0 files, 0 excluded`, index.RenderTree(utility.TreeOptions{MaxEntries: 2}))
+	}
+
+	t.Log("the scan detecting a platform has no diagnostics")
+	{
+		searchDir := createProject(t, map[string]string{
+			"pubspec.yaml": "name: app\n",
+		})
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, []string{"flutter"}, detectedScanners(result))
+		require.Nil(t, result.Diagnostics)
+	}
+}
+
+func Test_scanCache(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	searchDir := createProject(t, map[string]string{
+		"pubspec.yaml": "name: app\n",
+	})
+
+	t.Log("the second scan of the unchanged project reuses the cached scanner outputs")
+	{
+		options := scanner.Options{Cache: scanner.NewCache(cacheDir, "1.0.0")}
+
+		first := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, options)
+		require.Equal(t, 0, len(first.CachedScanners))
+
+		second := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, options)
+		require.True(t, sliceutil.IsStringInSlice("flutter", second.CachedScanners))
+		require.True(t, sliceutil.IsStringInSlice("android", second.CachedScanners))
+		// the cached options keep their serialized fields
+		firstOptions, err := json.Marshal(first.ScannerToOptionRoot)
+		require.NoError(t, err)
+		secondOptions, err := json.Marshal(second.ScannerToOptionRoot)
+		require.NoError(t, err)
+		require.Equal(t, string(firstOptions), string(secondOptions))
+		require.Equal(t, first.ScannerToBitriseConfigMap, second.ScannerToBitriseConfigMap)
+		require.Equal(t, first.ScannerToDetection, second.ScannerToDetection)
+	}
+
+	t.Log("a scanner runs again if one of its marker files changed")
+	{
+		require.NoError(t, ioutil.WriteFile(filepath.Join(searchDir, "pubspec.yaml"), []byte("name: renamed\n"), 0644))
+
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{Cache: scanner.NewCache(cacheDir, "1.0.0")})
+		require.False(t, sliceutil.IsStringInSlice("flutter", result.CachedScanners))
+		require.True(t, sliceutil.IsStringInSlice("android", result.CachedScanners))
+		require.Equal(t, []string{"flutter"}, detectedScanners(result))
+	}
+
+	t.Log("a scanner runs again if one of the app icons of its output changed")
+	{
+		resDir := filepath.Join("app", "src", "main", "res", "mipmap-hdpi")
+		iconProjectDir := createProject(t, map[string]string{
+			"settings.gradle":                        "include ':app'\n",
+			"build.gradle":                           "",
+			"gradlew":                                "",
+			"app/build.gradle":                       "apply plugin: 'com.android.application'\n",
+			filepath.Join(resDir, "ic_launcher.png"): "hdpi",
+		})
+		options := scanner.Options{Cache: scanner.NewCache(cacheDir, "1.0.0")}
+		first := scanner.ConfigContext(context.Background(), iconProjectDir, scanner.Filter{}, options)
+		require.Equal(t, 1, len(first.Icons))
+
+		require.NoError(t, ioutil.WriteFile(filepath.Join(iconProjectDir, resDir, "ic_launcher.png"), []byte("edited"), 0644))
+		edited := scanner.ConfigContext(context.Background(), iconProjectDir, scanner.Filter{}, options)
+		require.False(t, sliceutil.IsStringInSlice("android", edited.CachedScanners))
+
+		require.NoError(t, ioutil.WriteFile(filepath.Join(iconProjectDir, resDir, "ic_launcher_round.png"), []byte("round"), 0644))
+		added := scanner.ConfigContext(context.Background(), iconProjectDir, scanner.Filter{}, options)
+		require.False(t, sliceutil.IsStringInSlice("android", added.CachedScanners))
+		require.Equal(t, 2, len(added.Icons))
+	}
+
+	t.Log("the entries of a different version are not reused")
+	{
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{Cache: scanner.NewCache(cacheDir, "2.0.0")})
+		require.Equal(t, 0, len(result.CachedScanners))
+	}
+
+	t.Log("the scan is not cached without a cache")
+	{
+		result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+		require.Equal(t, 0, len(result.CachedScanners))
+	}
+}
+
+func Test_scanCacheEviction(t *testing.T) {
+	searchDir := createProject(t, map[string]string{
+		"pubspec.yaml": "name: app\n",
+	})
+	scan := func(cacheDir string) {
+		scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{Cache: scanner.NewCache(cacheDir, "1.0.0")})
+	}
+	writeEntry := func(t *testing.T, pth string, size int64, age time.Duration) {
+		require.NoError(t, ioutil.WriteFile(pth, nil, 0644))
+		require.NoError(t, os.Truncate(pth, size))
+		modTime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(pth, modTime, modTime))
+	}
+	listEntries := func(t *testing.T, cacheDir string) []os.FileInfo {
+		infos, err := ioutil.ReadDir(cacheDir)
+		require.NoError(t, err)
+		return infos
+	}
+	entryNames := func(t *testing.T, cacheDir string) []string {
+		var names []string
+		for _, info := range listEntries(t, cacheDir) {
+			names = append(names, info.Name())
+		}
+		return names
+	}
+
+	t.Log("the entries not used in the max age are removed, the temporary files of the interrupted scans included")
+	{
+		cacheDir := t.TempDir()
+		writeEntry(t, filepath.Join(cacheDir, "old.json"), 10, scanner.DefaultCacheMaxAge+time.Hour)
+		writeEntry(t, filepath.Join(cacheDir, "old.123.tmp"), 10, scanner.DefaultCacheMaxAge+time.Hour)
+		writeEntry(t, filepath.Join(cacheDir, "recent.json"), 10, scanner.DefaultCacheMaxAge-time.Hour)
+
+		scan(cacheDir)
+		names := entryNames(t, cacheDir)
+		require.Contains(t, names, "recent.json")
+		require.NotContains(t, names, "old.json")
+		require.NotContains(t, names, "old.123.tmp")
+	}
+
+	t.Log("the least recently used entries over the max size are removed")
+	{
+		cacheDir := t.TempDir()
+		writeEntry(t, filepath.Join(cacheDir, "large.json"), scanner.DefaultCacheMaxSize, time.Hour)
+
+		scan(cacheDir)
+		names := entryNames(t, cacheDir)
+		require.NotEmpty(t, names)
+		require.NotContains(t, names, "large.json")
+	}
+
+	t.Log("a cache hit marks the entry as used")
+	{
+		cacheDir := t.TempDir()
+		scan(cacheDir)
+
+		modTime := time.Now().Add(-48 * time.Hour)
+		for _, info := range listEntries(t, cacheDir) {
+			require.NoError(t, os.Chtimes(filepath.Join(cacheDir, info.Name()), modTime, modTime))
+		}
+
+		scan(cacheDir)
+		for _, info := range listEntries(t, cacheDir) {
+			require.True(t, time.Since(info.ModTime()) < time.Hour, info.Name())
+		}
+	}
+}
+
+func Test_newScanOutput(t *testing.T) {
+	resDir := filepath.Join("app", "src", "main", "res", "mipmap-hdpi")
+	searchDir := createProject(t, map[string]string{
+		"settings.gradle":                        "include ':app'\n",
+		"build.gradle":                           "",
+		"gradlew":                                "",
+		"app/build.gradle":                       "apply plugin: 'com.android.application'\n",
+		filepath.Join(resDir, "ic_launcher.png"): "hdpi",
+	})
+	result := scanner.ConfigContext(context.Background(), searchDir, scanner.Filter{}, scanner.Options{})
+	require.Equal(t, 1, len(result.Icons))
+
+	printed, err := newScanOutput(result)
+	require.NoError(t, err)
+
+	t.Log("the icons referenced by the options are inlined")
+	{
+		content, err := json.Marshal(printed)
+		require.NoError(t, err)
+
+		var decoded struct {
+			Options map[string]json.RawMessage `json:"options"`
+			Icons   map[string]string          `json:"icons"`
+		}
+		require.NoError(t, json.Unmarshal(content, &decoded))
+		require.Contains(t, decoded.Options, "android")
+		require.Equal(t, map[string]string{result.Icons[0].Filename: base64.StdEncoding.EncodeToString([]byte("hdpi"))}, decoded.Icons)
+		require.Contains(t, string(decoded.Options["android"]), result.Icons[0].Filename)
+	}
+
+	t.Log("the yaml output inlines the scan result too")
+	{
+		content, err := yaml.Marshal(printed)
+		require.NoError(t, err)
+		require.Contains(t, string(content), "options:")
+		require.Contains(t, string(content), "icons:\n  "+result.Icons[0].Filename+": "+base64.StdEncoding.EncodeToString([]byte("hdpi")))
+	}
+}
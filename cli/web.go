@@ -0,0 +1,479 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/scanner"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+)
+
+// wizardQuestion is an option of the option tree on the path selected by the answers.
+type wizardQuestion struct {
+	Key     string      `json:"key"`
+	Title   string      `json:"title"`
+	Summary string      `json:"summary,omitempty"`
+	Type    models.Type `json:"type"`
+	Values  []string    `json:"values,omitempty"`
+	Value   string      `json:"value"`
+}
+
+// wizardState is the state of the web wizard for the given answers.
+type wizardState struct {
+	Platforms []string         `json:"platforms"`
+	Platform  string           `json:"platform"`
+	Questions []wizardQuestion `json:"questions"`
+	Warnings  []string         `json:"warnings,omitempty"`
+	Icons     []string         `json:"icons,omitempty"`
+	Config    string           `json:"config,omitempty"`
+	Conflicts []string         `json:"conflicts,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// wizardHandler serves the web wizard of the scan result.
+type wizardHandler struct {
+	scanResult models.ScanResultModel
+	// generate returns the config to write for the selection and the merge conflicts
+	generate func(scanner.Selection) (bitriseModels.BitriseDataModel, []string, error)
+	// marshal returns the content of the generated config, as it is written
+	marshal func(bitriseModels.BitriseDataModel) ([]byte, error)
+	// write writes the outputs of the generated config
+	write func(bitriseModels.BitriseDataModel, scanner.Selection) error
+
+	// token is the random token of the run, required by every request
+	token string
+	// hosts are the Host headers accepted, the address the wizard is served on
+	hosts []string
+
+	mux      *http.ServeMux
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// wizardTokenKey is the query parameter, or the header (X-Wizard-Token) of the token
+const wizardTokenKey = "token"
+
+func newWizardToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate the web wizard token, error: %s", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func newWizardHandler(scanResult models.ScanResultModel,
+	generate func(scanner.Selection) (bitriseModels.BitriseDataModel, []string, error),
+	marshal func(bitriseModels.BitriseDataModel) ([]byte, error),
+	write func(bitriseModels.BitriseDataModel, scanner.Selection) error) (*wizardHandler, error) {
+	token, err := newWizardToken()
+	if err != nil {
+		return nil, err
+	}
+
+	h := &wizardHandler{
+		scanResult: scanResult,
+		generate:   generate,
+		marshal:    marshal,
+		write:      write,
+		token:      token,
+		mux:        http.NewServeMux(),
+		done:       make(chan struct{}),
+	}
+
+	h.mux.HandleFunc("/", h.servePage)
+	h.mux.HandleFunc("/icons/", h.serveIcon)
+	h.mux.HandleFunc("/api/state", h.serveState)
+	h.mux.HandleFunc("/api/write", h.serveWrite)
+	return h, nil
+}
+
+// allowAddr accepts the requests to the address, and to localhost on its port, if it is a loopback address.
+func (h *wizardHandler) allowAddr(addr net.Addr) {
+	h.hosts = append(h.hosts, addr.String())
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok && tcpAddr.IP.IsLoopback() {
+		h.hosts = append(h.hosts, fmt.Sprintf("localhost:%d", tcpAddr.Port))
+	}
+}
+
+func (h *wizardHandler) allowedHost(host string) bool {
+	for _, allowed := range h.hosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeHTTP serves the requests of the wizard page only: the Host has to be the address the wizard is served on,
+// against DNS rebinding, the Origin has to be the wizard page, if sent, and the token of the run is required.
+func (h *wizardHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.allowedHost(r.Host) {
+		http.Error(w, "invalid host", http.StatusForbidden)
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !h.allowedHost(strings.TrimPrefix(origin, "http://")) {
+		http.Error(w, "invalid origin", http.StatusForbidden)
+		return
+	}
+
+	token := r.URL.Query().Get(wizardTokenKey)
+	if token == "" {
+		token = r.Header.Get("X-Wizard-Token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		http.Error(w, "invalid token, open the URL printed by the command", http.StatusForbidden)
+		return
+	}
+
+	h.mux.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warnf("Failed to write response: %s", err)
+	}
+}
+
+func readAnswersRequest(w http.ResponseWriter, r *http.Request) (answers, bool) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, wizardState{Error: "method not allowed"})
+		return nil, false
+	}
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeJSON(w, http.StatusUnsupportedMediaType, wizardState{Error: "the answers have to be sent as application/json"})
+		return nil, false
+	}
+
+	a := answers{}
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		writeJSON(w, http.StatusBadRequest, wizardState{Error: fmt.Sprintf("failed to parse answers, error: %s", err)})
+		return nil, false
+	}
+	return a, true
+}
+
+// state walks the option tree of the answered platform and lists the questions on the selected path,
+// the unanswered questions are filled in with the recommended values.
+func (h *wizardHandler) state(a answers) (wizardState, scanner.Selection, error) {
+	var platforms []string
+	for platform := range h.scanResult.ScannerToOptionRoot {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	state := wizardState{Platforms: platforms, Questions: []wizardQuestion{}}
+	if len(platforms) == 0 {
+		return state, scanner.Selection{}, fmt.Errorf("no platform detected")
+	}
+
+	filled := answers{}
+	for key, value := range a {
+		filled[key] = value
+	}
+
+	state.Platform = platforms[0]
+	if _, ok := h.scanResult.ScannerToOptionRoot[a[platformAnswerKey]]; ok {
+		state.Platform = a[platformAnswerKey]
+	}
+	filled[platformAnswerKey] = state.Platform
+	state.Warnings = h.scanResult.ScannerToWarnings[state.Platform]
+
+	options := h.scanResult.ScannerToOptionRoot[state.Platform]
+	opt := &options
+	for opt != nil && opt.IsValueOption() {
+		question := wizardQuestion{
+			Key:     optionKey(*opt),
+			Title:   opt.Title,
+			Summary: opt.Summary,
+			Type:    opt.Type,
+		}
+		if opt.Type == models.TypeSelector || opt.Type == models.TypeOptionalSelector {
+			question.Values = opt.SortedValues()
+		}
+
+		value, answered := filled.lookup(*opt)
+		if !answered {
+			value = opt.RecommendedValue()
+			filled[question.Key] = value
+		}
+		question.Value = value
+		state.Questions = append(state.Questions, question)
+
+		next, ok := opt.ChildOptionMap[value]
+		if !ok && len(opt.ChildOptionMap) > 0 {
+			next = opt.ChildOptionMap[opt.SortedValues()[0]]
+		}
+		opt = next
+	}
+	if opt != nil {
+		state.Icons = opt.Icons
+	}
+
+	selection, err := answerSelection(h.scanResult, filled)
+	return state, selection, err
+}
+
+func (h *wizardHandler) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write([]byte(wizardPage)); err != nil {
+		log.Warnf("Failed to write response: %s", err)
+	}
+}
+
+func (h *wizardHandler) serveIcon(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/icons/")
+	for _, icon := range h.scanResult.Icons {
+		if icon.Filename == name {
+			http.ServeFile(w, r, icon.Path)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (h *wizardHandler) serveState(w http.ResponseWriter, r *http.Request) {
+	a, ok := readAnswersRequest(w, r)
+	if !ok {
+		return
+	}
+
+	state, selection, err := h.state(a)
+	if err != nil {
+		state.Error = err.Error()
+		writeJSON(w, http.StatusOK, state)
+		return
+	}
+
+	config, conflicts, err := h.generate(selection)
+	if err != nil {
+		state.Error = err.Error()
+		writeJSON(w, http.StatusOK, state)
+		return
+	}
+
+	configBytes, err := h.marshal(config)
+	if err != nil {
+		state.Error = fmt.Sprintf("failed to marshal bitrise config, error: %s", err)
+		writeJSON(w, http.StatusOK, state)
+		return
+	}
+
+	state.Config = string(configBytes)
+	state.Conflicts = conflicts
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (h *wizardHandler) serveWrite(w http.ResponseWriter, r *http.Request) {
+	a, ok := readAnswersRequest(w, r)
+	if !ok {
+		return
+	}
+
+	state, selection, err := h.state(a)
+	if err != nil {
+		state.Error = err.Error()
+		writeJSON(w, http.StatusBadRequest, state)
+		return
+	}
+
+	config, conflicts, err := h.generate(selection)
+	if err != nil {
+		state.Error = err.Error()
+		writeJSON(w, http.StatusBadRequest, state)
+		return
+	}
+	logMergeConflicts(conflicts)
+
+	if err := h.write(config, selection); err != nil {
+		state.Error = err.Error()
+		writeJSON(w, http.StatusInternalServerError, state)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, state)
+	h.doneOnce.Do(func() { close(h.done) })
+}
+
+func optionKey(opt models.OptionNode) string {
+	if opt.EnvKey != "" {
+		return opt.EnvKey
+	}
+	return opt.Title
+}
+
+// serveWizard serves the web wizard on the given address, until the files are written.
+func serveWizard(addr string, handler *wizardHandler) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s, error: %s", addr, err)
+	}
+
+	handler.allowAddr(listener.Addr())
+
+	server := &http.Server{Handler: handler}
+	go func() {
+		<-handler.done
+		if err := server.Shutdown(context.Background()); err != nil {
+			log.Warnf("Failed to stop the web wizard: %s", err)
+		}
+	}()
+
+	log.Infof("Open http://%s/?%s=%s in your browser to configure the project", listener.Addr(), wizardTokenKey, handler.token)
+
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("failed to serve the web wizard, error: %s", err)
+	}
+	return nil
+}
+
+const wizardPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>bitrise init</title>
+<style>
+body { font-family: sans-serif; margin: 0; display: flex; height: 100vh; }
+#form, #preview { padding: 1em 2em; overflow: auto; }
+#form { flex: 1; }
+#preview { flex: 1; background: #f6f6f6; }
+label { display: block; margin-top: 1em; font-weight: bold; }
+.summary { color: #666; font-size: 0.9em; margin: 0.2em 0; }
+select, input { width: 100%; padding: 0.3em; box-sizing: border-box; }
+img { height: 48px; margin-right: 0.5em; }
+.error { color: #b00; white-space: pre-wrap; }
+.warning { color: #a60; }
+button { margin-top: 2em; padding: 0.5em 2em; font-size: 1em; }
+</style>
+</head>
+<body>
+<div id="form">
+<h1>bitrise init</h1>
+<div id="icons"></div>
+<div id="questions"></div>
+<div id="messages"></div>
+<button id="write">Write files</button>
+<p id="result"></p>
+</div>
+<div id="preview"><h2>bitrise.yml</h2><pre id="config"></pre></div>
+<script>
+var answers = {};
+var custom = {};
+
+function el(tag, props, children) {
+	var e = document.createElement(tag);
+	Object.keys(props || {}).forEach(function (k) { e[k] = props[k]; });
+	(children || []).forEach(function (c) { e.appendChild(c); });
+	return e;
+}
+
+var token = new URLSearchParams(window.location.search).get("token") || "";
+
+function post(url) {
+	return fetch(url, {
+		method: "POST",
+		headers: { "Content-Type": "application/json", "X-Wizard-Token": token },
+		body: JSON.stringify(answers)
+	}).then(function (r) { return r.json(); });
+}
+
+function field(key, title, summary, input) {
+	var children = [el("label", { textContent: title })];
+	if (summary) { children.push(el("div", { className: "summary", textContent: summary })); }
+	children.push(input);
+	return el("div", {}, children);
+}
+
+function select(key, values, value, allowCustom) {
+	var s = el("select");
+	values.forEach(function (v) { s.appendChild(el("option", { value: v, textContent: v })); });
+	if (allowCustom) { s.appendChild(el("option", { value: "", textContent: "<custom value>" })); }
+	s.value = (allowCustom && (custom[key] || values.indexOf(value) === -1)) ? "" : value;
+	s.onchange = function () {
+		custom[key] = s.value === "";
+		answers[key] = s.value;
+		refresh();
+	};
+	return s;
+}
+
+function input(key, value) {
+	var i = el("input", { value: value });
+	i.onchange = function () { answers[key] = i.value; refresh(); };
+	return i;
+}
+
+function render(state) {
+	var questions = document.getElementById("questions");
+	questions.innerHTML = "";
+	if (state.platforms.length > 1) {
+		questions.appendChild(field("platform", "Platform", "", select("platform", state.platforms, state.platform, false)));
+	}
+	state.questions.forEach(function (q) {
+		var selector = q.type === "selector" || q.type === "selector_optional";
+		if (selector) {
+			var s = select(q.key, q.values, q.value, q.type === "selector_optional");
+			var children = [s];
+			if (s.value === "") { children.push(input(q.key, q.value)); }
+			questions.appendChild(field(q.key, q.title, q.summary, el("div", {}, children)));
+		} else {
+			questions.appendChild(field(q.key, q.title, q.summary, input(q.key, q.value)));
+		}
+	});
+
+	var icons = document.getElementById("icons");
+	icons.innerHTML = "";
+	(state.icons || []).forEach(function (icon) { icons.appendChild(el("img", { src: "/icons/" + encodeURIComponent(icon) + "?token=" + encodeURIComponent(token) })); });
+
+	var messages = document.getElementById("messages");
+	messages.innerHTML = "";
+	(state.warnings || []).concat(state.conflicts || []).forEach(function (w) {
+		messages.appendChild(el("p", { className: "warning", textContent: w }));
+	});
+	if (state.error) { messages.appendChild(el("p", { className: "error", textContent: state.error })); }
+
+	document.getElementById("config").textContent = state.config || "";
+	document.getElementById("write").disabled = !!state.error;
+}
+
+function refresh() {
+	return post("/api/state").then(render);
+}
+
+document.getElementById("write").onclick = function () {
+	post("/api/write").then(function (state) {
+		var result = document.getElementById("result");
+		if (state.error) {
+			result.className = "error";
+			result.textContent = state.error;
+			return;
+		}
+		result.className = "";
+		result.textContent = "Files written, you can close this page.";
+		document.getElementById("write").disabled = true;
+	});
+};
+
+refresh();
+</script>
+</body>
+</html>
+`
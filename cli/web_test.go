@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-init/models"
+	"github.com/bitrise-io/bitrise-init/scanner"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_wizardHandler(t *testing.T) {
+	scanResult := models.ScanResultModel{
+		ScannerToOptionRoot: map[string]models.OptionNode{"ios": testOptionTree()},
+		ScannerToBitriseConfigMap: map[string]models.BitriseConfigMap{
+			"ios": {"ios-config": "format_version: \"8\"\nproject_type: ios\n"},
+		},
+	}
+
+	var written []scanner.Selection
+	handler, err := newWizardHandler(scanResult, func(selection scanner.Selection) (bitriseModels.BitriseDataModel, []string, error) {
+		config, err := scanner.BuildConfig(scanResult, selection)
+		return config, nil, err
+	}, (*configDocument)(nil).marshal, func(config bitriseModels.BitriseDataModel, selection scanner.Selection) error {
+		written = append(written, selection)
+		return nil
+	})
+	require.NoError(t, err)
+	handler.allowAddr(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080})
+
+	request := func(pth, body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, pth, strings.NewReader(body))
+		r.Host = "127.0.0.1:8080"
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-Wizard-Token", handler.token)
+		return r
+	}
+
+	serve := func(r *http.Request) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, r)
+		return recorder.Code
+	}
+
+	post := func(pth, body string) (int, wizardState) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request(pth, body))
+
+		var state wizardState
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &state))
+		return recorder.Code, state
+	}
+
+	t.Log("the requests without the token of the run, or from another site are rejected")
+	{
+		r := request("/api/write", `{}`)
+		r.Header.Del("X-Wizard-Token")
+		require.Equal(t, http.StatusForbidden, serve(r))
+
+		r = request("/api/write?token=invalid", `{}`)
+		r.Header.Del("X-Wizard-Token")
+		require.Equal(t, http.StatusForbidden, serve(r))
+
+		r = request("/api/write", `{}`)
+		r.Host = "attacker.example.com:8080"
+		require.Equal(t, http.StatusForbidden, serve(r))
+
+		r = request("/api/write", `{}`)
+		r.Header.Set("Origin", "http://attacker.example.com")
+		require.Equal(t, http.StatusForbidden, serve(r))
+
+		r = request("/api/write", `{}`)
+		r.Header.Set("Content-Type", "text/plain")
+		require.Equal(t, http.StatusUnsupportedMediaType, serve(r))
+
+		require.Equal(t, 0, len(written))
+	}
+
+	t.Log("the page is served with the token in the URL, on localhost too")
+	{
+		r := httptest.NewRequest(http.MethodGet, "/?token="+handler.token, nil)
+		r.Host = "localhost:8080"
+		r.Header.Set("Origin", "http://localhost:8080")
+		require.Equal(t, http.StatusOK, serve(r))
+	}
+
+	t.Log("unanswered questions are filled in with the recommended values")
+	{
+		code, state := post("/api/state", `{}`)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "", state.Error)
+		require.Equal(t, []string{"ios"}, state.Platforms)
+		require.Equal(t, "ios", state.Platform)
+		require.Equal(t, []wizardQuestion{
+			{Key: "PROJECT_PATH", Title: "Project path", Type: models.TypeSelector, Values: []string{"App.xcodeproj"}, Value: "App.xcodeproj"},
+			{Key: "SCHEME", Title: "Scheme", Type: models.TypeSelector, Values: []string{"App", "AppTests"}, Value: "App"},
+			{Key: "EXPORT_METHOD", Title: "Export method", Type: models.TypeSelector, Values: []string{"app-store", "development"}, Value: "app-store"},
+			{Key: "VARIANT", Title: "Variant", Type: models.TypeOptionalUserInput, Value: ""},
+		}, state.Questions)
+		require.Contains(t, state.Config, "project_type: ios")
+		require.Contains(t, state.Config, "EXPORT_METHOD: app-store")
+	}
+
+	t.Log("invalid answers are reported")
+	{
+		code, state := post("/api/state", `{"SCHEME": "Unknown"}`)
+		require.Equal(t, http.StatusOK, code)
+		require.Contains(t, state.Error, "- Scheme (SCHEME): invalid answer (Unknown), allowed values: App, AppTests")
+		require.Equal(t, "", state.Config)
+
+		code, state = post("/api/write", `{"SCHEME": "Unknown"}`)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Equal(t, 0, len(written))
+	}
+
+	t.Log("writes the selected config")
+	{
+		code, state := post("/api/write", `{"SCHEME": "AppTests", "Export method": "development"}`)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "", state.Error)
+		require.Equal(t, []scanner.Selection{{
+			Platform: "ios",
+			Answers: []scanner.OptionAnswer{
+				{Title: "Project path", EnvKey: "PROJECT_PATH", Value: "App.xcodeproj"},
+				{Title: "Scheme", EnvKey: "SCHEME", Value: "AppTests"},
+				{Title: "Export method", EnvKey: "EXPORT_METHOD", Value: "development"},
+				{Title: "Variant", EnvKey: "VARIANT", Value: ""},
+			},
+			ConfigName: "ios-config",
+		}}, written)
+
+		select {
+		case <-handler.done:
+		default:
+			t.Fatal("wizard not done after writing the files")
+		}
+	}
+}